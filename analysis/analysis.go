@@ -0,0 +1,171 @@
+// Package analysis builds a structured, tool-friendly summary of a SQL
+// statement on top of the core postgresparser IR: projected columns, tables
+// (base and CTE), join/filter/order column usage, and DML-specific
+// metadata such as upsert and RETURNING clauses.
+package analysis
+
+// SQLCommand identifies the top-level command an AnalysisResult describes.
+type SQLCommand string
+
+const (
+	SQLCommandSelect   SQLCommand = "SELECT"
+	SQLCommandInsert   SQLCommand = "INSERT"
+	SQLCommandUpdate   SQLCommand = "UPDATE"
+	SQLCommandDelete   SQLCommand = "DELETE"
+	SQLCommandCreate   SQLCommand = "CREATE"
+	SQLCommandAlter    SQLCommand = "ALTER"
+	SQLCommandDrop     SQLCommand = "DROP"
+	SQLCommandTruncate SQLCommand = "TRUNCATE"
+	SQLCommandComment  SQLCommand = "COMMENT"
+	SQLCommandGrant    SQLCommand = "GRANT"
+	SQLCommandRevoke   SQLCommand = "REVOKE"
+	SQLCommandUnknown  SQLCommand = "UNKNOWN"
+)
+
+// SQLTableType distinguishes a base table reference from a CTE reference.
+type SQLTableType string
+
+const (
+	SQLTableTypeBase SQLTableType = "base"
+	SQLTableTypeCTE  SQLTableType = "cte"
+)
+
+// SQLUsageType classifies why a column appears in a statement.
+type SQLUsageType string
+
+const (
+	SQLUsageTypeFilter    SQLUsageType = "filter"
+	SQLUsageTypeOrder     SQLUsageType = "order"
+	SQLUsageTypeJoin      SQLUsageType = "join"
+	SQLUsageTypeDMLSet    SQLUsageType = "dml_set"
+	SQLUsageTypeReturning SQLUsageType = "returning"
+)
+
+// Table is a table reference found in a statement, either a base table or a
+// CTE defined earlier in the same statement.
+type Table struct {
+	Name  string
+	Alias string
+	Type  SQLTableType
+}
+
+// Column is a column projected by a SELECT list.
+type Column struct {
+	Name       string
+	Alias      string
+	TableAlias string
+}
+
+// CTE captures a single WITH entry's name and the query text that defines
+// it.
+type CTE struct {
+	Name  string
+	Query string
+}
+
+// OrderByItem is one entry of an ORDER BY clause.
+type OrderByItem struct {
+	Expression string
+	Descending bool
+}
+
+// ColumnUsage records a single reason a column was referenced, so callers
+// can answer questions like "which columns filter this table" without
+// re-walking the parse tree.
+type ColumnUsage struct {
+	Column     string
+	TableAlias string
+	UsageType  SQLUsageType
+}
+
+// Upsert describes an INSERT ... ON CONFLICT clause.
+type Upsert struct {
+	Action     string
+	SetClauses []string
+}
+
+// Parameter is a single placeholder found in the statement, in whichever of
+// the "?", "$N", or ":name" dialects the statement used.
+type Parameter struct {
+	// Ordinal is the placeholder's 1-based position in canonical order:
+	// for "?" and ":name" forms this is assignment order (with repeated
+	// ":name" occurrences sharing an ordinal), and for "$N" forms it is N
+	// itself.
+	Ordinal int
+	// Name is set for ":name" placeholders. For a placeholder inside an
+	// INSERT ... VALUES list it is instead the name of the target column,
+	// replacing any ":name". It is empty otherwise.
+	Name string
+	// InferredType is the PostgreSQL type named by an explicit "::type"
+	// cast on the placeholder, and empty otherwise; the type of a target
+	// or compared column is not looked up.
+	InferredType string
+	// Nullable would reflect the target column's NOT NULL constraint, but
+	// AnalyzeSQL parses one statement at a time with no catalog to
+	// consult, so it is always false until a schema-aware caller fills
+	// it in.
+	Nullable bool
+	Source       ParameterSource
+	Location     Location
+}
+
+// ParameterSource records which part of the statement a Parameter was
+// found in (a cast, a SET assignment, an INSERT value, a comparison, or a
+// function argument).
+type ParameterSource string
+
+const (
+	ParameterSourceInsertColumn ParameterSource = "insert_column"
+	ParameterSourceUpdateSet    ParameterSource = "update_set"
+	ParameterSourceComparison   ParameterSource = "comparison"
+	ParameterSourceCast         ParameterSource = "cast"
+	ParameterSourceFunctionArg  ParameterSource = "function_arg"
+	ParameterSourceUnknown      ParameterSource = "unknown"
+)
+
+// Location is a 1-based line/column position within an AnalysisResult's
+// Source.
+type Location struct {
+	Line int
+	Col  int
+}
+
+// AnalysisResult is the DTO returned by AnalyzeSQL.
+type AnalysisResult struct {
+	Command SQLCommand
+
+	Tables      []Table
+	Columns     []Column
+	CTEs        []CTE
+	Limit       *int
+	OrderBy     []OrderByItem
+	ColumnUsage []ColumnUsage
+
+	InsertColumns []string
+	Upsert        *Upsert
+	Returning     []string
+	Parameters    []Parameter
+
+	// SourceOffset is the byte offset, in the source passed to
+	// AnalyzeSQLAll, of the statement this result describes. It is zero
+	// for results produced directly by AnalyzeSQL.
+	SourceOffset int
+
+	// Source is the exact statement text AnalyzeSQL parsed to produce
+	// this result, letting downstream packages (e.g. analysis/normalize)
+	// reuse it instead of re-parsing.
+	Source string
+
+	// ReadOnly, HasSideEffects, and RequiresTransaction classify the
+	// statement for dispatch: ReadOnly statements are safe to route to a
+	// read replica, HasSideEffects statements must go to the primary,
+	// and RequiresTransaction statements cannot run inside an implicit
+	// transaction block. They are populated by AnalyzeSQL via classify.
+	ReadOnly            bool
+	HasSideEffects      bool
+	RequiresTransaction bool
+
+	// Schema is populated for CREATE/ALTER/DROP/TRUNCATE/COMMENT/GRANT/
+	// REVOKE statements, describing the object the statement affects.
+	Schema *SchemaChange
+}
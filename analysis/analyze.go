@@ -0,0 +1,602 @@
+package analysis
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tsingson/postgresparser"
+	"github.com/tsingson/postgresparser/internal/sqltoken"
+	"github.com/tsingson/postgresparser/internal/stmtsplit"
+)
+
+// AnalyzeSQL parses sql's first statement and returns the structured
+// AnalysisResult describing it. sql may contain more than one statement, in
+// which case only the first is analyzed; use AnalyzeSQLAll to process every
+// statement in a batch.
+func AnalyzeSQL(sql string) (*AnalysisResult, error) {
+	stmts, err := stmtsplit.Split(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("analysis: empty statement")
+	}
+	source := stmts[0].SQL
+
+	toks, err := sqltoken.Lex(source)
+	if err != nil {
+		return nil, err
+	}
+	toks = sqltoken.NonTrivia(toks)
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("analysis: empty statement")
+	}
+	if err := checkBalanced(toks); err != nil {
+		return nil, err
+	}
+
+	if postgresparser.IsUtilityStatement(toks) {
+		if err := postgresparser.ValidateUtilityStatement(toks); err != nil {
+			return nil, err
+		}
+		res := &AnalysisResult{Command: SQLCommandUnknown, Source: source}
+		res.classify(toks)
+		return res, nil
+	}
+
+	res := &AnalysisResult{Source: source}
+
+	rest := toks
+	if rest[0].Is("WITH") {
+		ctes, remaining, err := parseCTEs(source, rest[1:])
+		if err != nil {
+			return nil, err
+		}
+		res.CTEs = ctes
+		rest = remaining
+		res.Tables = append(res.Tables, cteBaseTables(ctes)...)
+	}
+	if len(rest) == 0 {
+		return nil, fmt.Errorf("analysis: expected a statement after WITH")
+	}
+
+	cteNames := make(map[string]bool, len(res.CTEs))
+	for _, c := range res.CTEs {
+		cteNames[c.Name] = true
+	}
+
+	switch {
+	case rest[0].Is("SELECT"):
+		parseSelect(res, rest, cteNames)
+	case rest[0].Is("INSERT"):
+		if err := parseInsert(res, rest); err != nil {
+			return nil, err
+		}
+	case rest[0].Is("UPDATE"):
+		res.Command = SQLCommandUpdate
+	case rest[0].Is("DELETE"):
+		res.Command = SQLCommandDelete
+	case rest[0].Is("CREATE"):
+		if err := parseCreate(res, rest); err != nil {
+			return nil, err
+		}
+	case rest[0].Is("ALTER"):
+		if err := parseAlterTable(res, rest); err != nil {
+			return nil, err
+		}
+	case rest[0].Is("DROP"):
+		if err := parseDrop(res, rest); err != nil {
+			return nil, err
+		}
+	case rest[0].Is("TRUNCATE"):
+		parseTruncate(res, rest)
+	case rest[0].Is("COMMENT"):
+		parseComment(res, rest)
+	case rest[0].Is("VACUUM"), rest[0].Is("REINDEX"):
+		// Maintenance commands: classify (via RequiresTransaction) cares
+		// about these, but they have no SELECT/DML/DDL shape of their own.
+		res.Command = SQLCommandUnknown
+	case rest[0].Is("GRANT"):
+		parseGrantRevoke(res, rest, SQLCommandGrant)
+	case rest[0].Is("REVOKE"):
+		parseGrantRevoke(res, rest, SQLCommandRevoke)
+	default:
+		return nil, fmt.Errorf("analysis: unrecognized statement starting with %q", rest[0].Text)
+	}
+
+	_, params := CanonicalizePlaceholders(source)
+	res.Parameters = params
+	res.inferParameterTypes(toks)
+	res.classify(toks)
+
+	return res, nil
+}
+
+func checkBalanced(toks []sqltoken.Token) error {
+	depth := 0
+	for _, t := range toks {
+		if t.Kind != sqltoken.Punct {
+			continue
+		}
+		switch t.Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("analysis: unmatched ')' at line %d", t.Line)
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("analysis: unmatched '('")
+	}
+	return nil
+}
+
+func findMatchingParen(toks []sqltoken.Token, openIdx int) int {
+	depth := 0
+	for i := openIdx; i < len(toks); i++ {
+		if toks[i].Kind != sqltoken.Punct {
+			continue
+		}
+		switch toks[i].Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseCTEs parses the comma-separated "name [(cols)] AS [[NOT] MATERIALIZED]
+// (query)" list that follows a WITH keyword, returning the parsed CTEs and
+// the remaining tokens of the main statement.
+func parseCTEs(source string, toks []sqltoken.Token) ([]CTE, []sqltoken.Token, error) {
+	var ctes []CTE
+	rest := toks
+	for {
+		if len(rest) == 0 || rest[0].Kind != sqltoken.Ident {
+			return nil, nil, fmt.Errorf("analysis: expected a CTE name after WITH")
+		}
+		name := rest[0].Text
+		i := 1
+		if i < len(rest) && rest[i].Kind == sqltoken.Punct && rest[i].Text == "(" {
+			close := findMatchingParen(rest, i)
+			if close < 0 {
+				return nil, nil, fmt.Errorf("analysis: unmatched '(' in CTE column list for %s", name)
+			}
+			i = close + 1
+		}
+		if i >= len(rest) || !rest[i].Is("AS") {
+			return nil, nil, fmt.Errorf("analysis: expected AS in CTE definition for %s", name)
+		}
+		i++
+		if i < len(rest) && rest[i].Is("MATERIALIZED") {
+			i++
+		} else if i+1 < len(rest) && rest[i].Is("NOT") && rest[i+1].Is("MATERIALIZED") {
+			i += 2
+		}
+		if i >= len(rest) || rest[i].Kind != sqltoken.Punct || rest[i].Text != "(" {
+			return nil, nil, fmt.Errorf("analysis: expected '(' to open CTE query for %s", name)
+		}
+		openIdx := i
+		closeIdx := findMatchingParen(rest, openIdx)
+		if closeIdx < 0 {
+			return nil, nil, fmt.Errorf("analysis: unmatched '(' in CTE query for %s", name)
+		}
+		query := ""
+		if closeIdx > openIdx+1 {
+			query = trimSlice(source, rest[openIdx+1].Pos, rest[closeIdx].Pos)
+		}
+		ctes = append(ctes, CTE{Name: name, Query: query})
+
+		rest = rest[closeIdx+1:]
+		if len(rest) > 0 && rest[0].Kind == sqltoken.Punct && rest[0].Text == "," {
+			rest = rest[1:]
+			continue
+		}
+		break
+	}
+	return ctes, rest, nil
+}
+
+func trimSlice(s string, start, end int) string {
+	for start < end && isSpaceByte(s[start]) {
+		start++
+	}
+	for end > start && isSpaceByte(s[end-1]) {
+		end--
+	}
+	return s[start:end]
+}
+
+func isSpaceByte(b byte) bool { return b == ' ' || b == '\t' || b == '\n' || b == '\r' }
+
+// cteBaseTables extracts the base tables referenced in each CTE's own FROM
+// clause, so callers get a complete table list without having to walk each
+// CTE's query text themselves.
+func cteBaseTables(ctes []CTE) []Table {
+	var tables []Table
+	for _, cte := range ctes {
+		innerToks, err := sqltoken.Lex(cte.Query)
+		if err != nil {
+			continue
+		}
+		innerToks = sqltoken.NonTrivia(innerToks)
+		fromIdx := sqltoken.FindKeyword(innerToks, "FROM")
+		if fromIdx < 0 {
+			continue
+		}
+		end := len(innerToks)
+		for _, kw := range [][]string{{"WHERE"}, {"GROUP", "BY"}, {"ORDER", "BY"}, {"LIMIT"}} {
+			idx := sqltoken.FindPhrase(innerToks, kw...)
+			if idx >= 0 && idx < end {
+				end = idx
+			}
+		}
+		inner, _ := parseFromClause(innerToks[fromIdx+1:end], nil)
+		tables = appendUniqueTables(tables, inner)
+	}
+	return tables
+}
+
+func appendUniqueTables(tables []Table, add []Table) []Table {
+	for _, t := range add {
+		dup := false
+		for _, existing := range tables {
+			if existing.Name == t.Name && existing.Alias == t.Alias && existing.Type == t.Type {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+func parseSelect(res *AnalysisResult, toks []sqltoken.Token, cteNames map[string]bool) {
+	res.Command = SQLCommandSelect
+
+	fromIdx := sqltoken.FindKeyword(toks, "FROM")
+	whereIdx := sqltoken.FindKeyword(toks, "WHERE")
+	groupIdx := sqltoken.FindPhrase(toks, "GROUP", "BY")
+	orderIdx := sqltoken.FindPhrase(toks, "ORDER", "BY")
+	limitIdx := sqltoken.FindKeyword(toks, "LIMIT")
+
+	selectEnd := len(toks)
+	for _, idx := range []int{fromIdx, whereIdx, groupIdx, orderIdx, limitIdx} {
+		if idx >= 0 && idx < selectEnd {
+			selectEnd = idx
+		}
+	}
+	res.Columns = parseProjection(toks[1:selectEnd])
+
+	if fromIdx >= 0 {
+		fromEnd := len(toks)
+		for _, idx := range []int{whereIdx, groupIdx, orderIdx, limitIdx} {
+			if idx >= 0 && idx < fromEnd {
+				fromEnd = idx
+			}
+		}
+		tables, usage := parseFromClause(toks[fromIdx+1:fromEnd], cteNames)
+		res.Tables = appendUniqueTables(res.Tables, tables)
+		res.ColumnUsage = append(res.ColumnUsage, usage...)
+	}
+
+	if whereIdx >= 0 {
+		whereEnd := len(toks)
+		for _, idx := range []int{groupIdx, orderIdx, limitIdx} {
+			if idx >= 0 && idx < whereEnd {
+				whereEnd = idx
+			}
+		}
+		res.ColumnUsage = append(res.ColumnUsage, parseFilterColumns(toks[whereIdx+1:whereEnd])...)
+	}
+
+	if orderIdx >= 0 {
+		orderEnd := len(toks)
+		if limitIdx >= 0 && limitIdx > orderIdx {
+			orderEnd = limitIdx
+		}
+		items, usage := parseOrderBy(toks[orderIdx+2 : orderEnd])
+		res.OrderBy = items
+		res.ColumnUsage = append(res.ColumnUsage, usage...)
+	}
+
+	if limitIdx >= 0 && limitIdx+1 < len(toks) && toks[limitIdx+1].Kind == sqltoken.Number {
+		if n, err := strconv.Atoi(toks[limitIdx+1].Text); err == nil {
+			res.Limit = &n
+		}
+	}
+}
+
+func parseProjection(toks []sqltoken.Token) []Column {
+	var cols []Column
+	for _, group := range sqltoken.SplitTopLevel(toks, ",") {
+		if len(group) == 0 {
+			continue
+		}
+		expr := group
+		alias := ""
+		if asIdx := sqltoken.FindKeyword(group, "AS"); asIdx >= 0 {
+			expr = group[:asIdx]
+			if asIdx+1 < len(group) {
+				alias = group[asIdx+1].Text
+			}
+		}
+		name, tableAlias := exprColumnName(expr)
+		cols = append(cols, Column{Name: name, Alias: alias, TableAlias: tableAlias})
+	}
+	return cols
+}
+
+func exprColumnName(expr []sqltoken.Token) (name, tableAlias string) {
+	switch {
+	case len(expr) == 3 && expr[0].Kind == sqltoken.Ident && expr[1].Text == "." && expr[2].Kind == sqltoken.Ident:
+		return expr[2].Text, expr[0].Text
+	case len(expr) == 1 && expr[0].Kind == sqltoken.Ident:
+		return expr[0].Text, ""
+	case len(expr) > 0:
+		return expr[len(expr)-1].Text, ""
+	default:
+		return "", ""
+	}
+}
+
+var joinTypeAdjectives = map[string]bool{
+	"INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true, "CROSS": true,
+}
+
+var fromBoundaryKeywords = map[string]bool{
+	"ON": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true,
+	"FULL": true, "OUTER": true, "CROSS": true,
+}
+
+// parseFromClause walks the tokens between FROM and the next clause,
+// extracting one Table per comma- or JOIN-separated table reference and one
+// Join ColumnUsage per column (qualified or bare) on either side of each
+// "ON a = b" condition.
+func parseFromClause(toks []sqltoken.Token, cteNames map[string]bool) ([]Table, []ColumnUsage) {
+	var tables []Table
+	var usage []ColumnUsage
+	n := len(toks)
+	i := 0
+	for i < n {
+		for i < n && toks[i].Kind == sqltoken.Ident && joinTypeAdjectives[toks[i].Upper()] {
+			i++
+		}
+		if i < n && toks[i].Is("JOIN") {
+			i++
+		}
+		if i >= n || toks[i].Kind != sqltoken.Ident {
+			i++
+			continue
+		}
+		name := toks[i].Text
+		i++
+		alias := ""
+		if i < n && toks[i].Is("AS") {
+			i++
+			if i < n && toks[i].Kind == sqltoken.Ident {
+				alias = toks[i].Text
+				i++
+			}
+		} else if i < n && toks[i].Kind == sqltoken.Ident && !fromBoundaryKeywords[toks[i].Upper()] {
+			alias = toks[i].Text
+			i++
+		}
+		tt := SQLTableTypeBase
+		if cteNames[name] {
+			tt = SQLTableTypeCTE
+		}
+		tables = append(tables, Table{Name: name, Alias: alias, Type: tt})
+
+		if i < n && toks[i].Is("ON") {
+			i++
+			condStart := i
+			for i < n && !(toks[i].Kind == sqltoken.Ident && fromBoundaryKeywords[toks[i].Upper()]) {
+				i++
+			}
+			usage = append(usage, parseJoinCondition(toks[condStart:i])...)
+			continue
+		}
+		if i < n && toks[i].Kind == sqltoken.Punct && toks[i].Text == "," {
+			i++
+		}
+	}
+	return tables, usage
+}
+
+func parseJoinCondition(toks []sqltoken.Token) []ColumnUsage {
+	var usage []ColumnUsage
+	for _, clause := range splitByKeyword(toks, "AND") {
+		eqIdx := findOpIndex(clause, "=")
+		if eqIdx < 0 {
+			continue
+		}
+		if u, ok := qualifiedColumnUsage(clause[:eqIdx], SQLUsageTypeJoin); ok {
+			usage = append(usage, u)
+		}
+		if u, ok := qualifiedColumnUsage(clause[eqIdx+1:], SQLUsageTypeJoin); ok {
+			usage = append(usage, u)
+		}
+	}
+	return usage
+}
+
+func parseFilterColumns(toks []sqltoken.Token) []ColumnUsage {
+	var usage []ColumnUsage
+	for _, clause := range splitByKeyword(toks, "AND") {
+		opIdx := -1
+		for _, op := range []string{"<=", ">=", "<>", "!=", "=", "<", ">"} {
+			if idx := findOpIndex(clause, op); idx >= 0 {
+				opIdx = idx
+				break
+			}
+		}
+		if opIdx < 0 {
+			continue
+		}
+		if u, ok := qualifiedColumnUsage(clause[:opIdx], SQLUsageTypeFilter); ok {
+			usage = append(usage, u)
+		}
+	}
+	return usage
+}
+
+func parseOrderBy(toks []sqltoken.Token) ([]OrderByItem, []ColumnUsage) {
+	var items []OrderByItem
+	var usage []ColumnUsage
+	for _, group := range sqltoken.SplitTopLevel(toks, ",") {
+		if len(group) == 0 {
+			continue
+		}
+		expr := group
+		desc := false
+		if last := group[len(group)-1]; last.Is("DESC") {
+			desc = true
+			expr = group[:len(group)-1]
+		} else if last.Is("ASC") {
+			expr = group[:len(group)-1]
+		}
+		items = append(items, OrderByItem{Expression: sqltoken.Text(expr), Descending: desc})
+		if u, ok := qualifiedColumnUsage(expr, SQLUsageTypeOrder); ok {
+			usage = append(usage, u)
+		}
+	}
+	return items, usage
+}
+
+func qualifiedColumnUsage(toks []sqltoken.Token, ut SQLUsageType) (ColumnUsage, bool) {
+	switch {
+	case len(toks) == 3 && toks[0].Kind == sqltoken.Ident && toks[1].Text == "." && toks[2].Kind == sqltoken.Ident:
+		return ColumnUsage{Column: toks[2].Text, TableAlias: toks[0].Text, UsageType: ut}, true
+	case len(toks) == 1 && toks[0].Kind == sqltoken.Ident:
+		return ColumnUsage{Column: toks[0].Text, UsageType: ut}, true
+	default:
+		return ColumnUsage{}, false
+	}
+}
+
+func splitByKeyword(toks []sqltoken.Token, kw string) [][]sqltoken.Token {
+	depths := sqltoken.Depths(toks)
+	var groups [][]sqltoken.Token
+	start := 0
+	for i, t := range toks {
+		if depths[i] == 0 && t.Is(kw) {
+			groups = append(groups, toks[start:i])
+			start = i + 1
+		}
+	}
+	groups = append(groups, toks[start:])
+	return groups
+}
+
+func findOpIndex(toks []sqltoken.Token, op string) int {
+	depths := sqltoken.Depths(toks)
+	for i, t := range toks {
+		if depths[i] == 0 && t.Kind == sqltoken.Punct && t.Text == op {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseInsert(res *AnalysisResult, toks []sqltoken.Token) error {
+	res.Command = SQLCommandInsert
+	i := 1
+	if i < len(toks) && toks[i].Is("INTO") {
+		i++
+	}
+	if i >= len(toks) || toks[i].Kind != sqltoken.Ident {
+		return fmt.Errorf("analysis: expected a table name after INSERT INTO")
+	}
+	i++
+	if i < len(toks) && toks[i].Kind == sqltoken.Punct && toks[i].Text == "(" {
+		close := findMatchingParen(toks, i)
+		if close < 0 {
+			return fmt.Errorf("analysis: unmatched '(' in INSERT column list")
+		}
+		for _, g := range sqltoken.SplitTopLevel(toks[i+1:close], ",") {
+			if len(g) > 0 {
+				res.InsertColumns = append(res.InsertColumns, g[0].Text)
+			}
+		}
+		i = close + 1
+	}
+
+	returningIdx := sqltoken.FindKeyword(toks, "RETURNING")
+	conflictIdx := sqltoken.FindPhrase(toks, "ON", "CONFLICT")
+
+	if returningIdx >= 0 {
+		for _, g := range sqltoken.SplitTopLevel(toks[returningIdx+1:], ",") {
+			if len(g) == 0 {
+				continue
+			}
+			col := g[len(g)-1].Text
+			res.Returning = append(res.Returning, col)
+			res.ColumnUsage = append(res.ColumnUsage, ColumnUsage{Column: col, UsageType: SQLUsageTypeReturning})
+		}
+	}
+
+	if conflictIdx >= 0 {
+		upsertEnd := len(toks)
+		if returningIdx >= 0 && returningIdx > conflictIdx {
+			upsertEnd = returningIdx
+		}
+		upsert, usage, err := parseUpsert(toks[conflictIdx+2 : upsertEnd])
+		if err != nil {
+			return err
+		}
+		res.Upsert = upsert
+		res.ColumnUsage = append(res.ColumnUsage, usage...)
+	}
+
+	return nil
+}
+
+func parseUpsert(toks []sqltoken.Token) (*Upsert, []ColumnUsage, error) {
+	i := 0
+	if i < len(toks) && toks[i].Kind == sqltoken.Punct && toks[i].Text == "(" {
+		close := findMatchingParen(toks, i)
+		if close < 0 {
+			return nil, nil, fmt.Errorf("analysis: unmatched '(' in ON CONFLICT target")
+		}
+		i = close + 1
+	}
+	if i >= len(toks) || !toks[i].Is("DO") {
+		return nil, nil, fmt.Errorf("analysis: expected DO in ON CONFLICT clause")
+	}
+	i++
+	if i < len(toks) && toks[i].Is("NOTHING") {
+		return &Upsert{Action: "DO NOTHING"}, nil, nil
+	}
+	if i >= len(toks) || !toks[i].Is("UPDATE") {
+		return nil, nil, fmt.Errorf("analysis: expected UPDATE or NOTHING after DO")
+	}
+	i++
+	if i >= len(toks) || !toks[i].Is("SET") {
+		return nil, nil, fmt.Errorf("analysis: expected SET after DO UPDATE")
+	}
+	i++
+
+	var setClauses []string
+	var usage []ColumnUsage
+	for _, g := range sqltoken.SplitTopLevel(toks[i:], ",") {
+		if len(g) == 0 {
+			continue
+		}
+		setClauses = append(setClauses, sqltoken.Text(g))
+		if g[0].Kind == sqltoken.Ident {
+			usage = append(usage, ColumnUsage{Column: g[0].Text, UsageType: SQLUsageTypeDMLSet})
+		}
+	}
+	return &Upsert{Action: "DO UPDATE", SetClauses: setClauses}, usage, nil
+}
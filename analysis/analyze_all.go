@@ -0,0 +1,30 @@
+package analysis
+
+import "github.com/tsingson/postgresparser/internal/stmtsplit"
+
+// AnalyzeSQLAll splits sql into its constituent statements and analyzes each
+// one independently, returning one AnalysisResult per statement in source
+// order. Unlike AnalyzeSQL, which only looks at the first statement of a
+// batch, AnalyzeSQLAll lets tooling such as linters, replayers, and
+// migration checkers reason about an entire script and, via
+// AnalysisResult.SourceOffset, point findings back at the original text.
+func AnalyzeSQLAll(sql string) ([]*AnalysisResult, error) {
+	stmts, err := stmtsplit.Split(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*AnalysisResult, 0, len(stmts))
+	for _, stmt := range stmts {
+		res, err := AnalyzeSQL(stmt.SQL)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			continue
+		}
+		res.SourceOffset = stmt.Offset
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,28 @@
+package analysis
+
+import "testing"
+
+func TestAnalyzeSQLAllSplitsAndOffsetsStatements(t *testing.T) {
+	results, err := AnalyzeSQLAll("SELECT 1; INSERT INTO t (id) VALUES (1); SET client_min_messages = warning;")
+	if err != nil {
+		t.Fatalf("AnalyzeSQLAll failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d: %+v", len(results), results)
+	}
+	if results[0].Command != SQLCommandSelect || results[0].SourceOffset != 0 {
+		t.Fatalf("unexpected first result: %+v", results[0])
+	}
+	if results[1].Command != SQLCommandInsert || results[1].SourceOffset == 0 {
+		t.Fatalf("unexpected second result: %+v", results[1])
+	}
+	if results[2].Command != SQLCommandUnknown {
+		t.Fatalf("unexpected third result: %+v", results[2])
+	}
+}
+
+func TestAnalyzeSQLAllStopsAtFirstError(t *testing.T) {
+	if _, err := AnalyzeSQLAll("SELECT 1; SHOW;"); err == nil {
+		t.Fatalf("expected error from malformed second statement")
+	}
+}
@@ -0,0 +1,147 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/tsingson/postgresparser/internal/sqltoken"
+)
+
+var volatileFuncNames = map[string]bool{
+	"nextval": true, "setval": true, "pg_advisory_lock": true, "pg_advisory_xact_lock": true,
+}
+
+// classify populates ReadOnly, HasSideEffects, and RequiresTransaction on
+// res from toks (the token stream AnalyzeSQL already lexed for res.Source)
+// and res's already-extracted Command/CTEs, so proxies and routers built on
+// this parser can dispatch statements to a read replica or a primary
+// without re-implementing statement classification themselves. Working
+// from tokens rather than res.Source's raw text means a string literal or
+// comment that happens to contain a keyword-like substring (e.g. a comment
+// mentioning "FOR UPDATE", or a literal containing "nextval(") is never
+// mistaken for the real thing. AnalyzeSQL calls classify once the rest of
+// res is populated.
+func (res *AnalysisResult) classify(toks []sqltoken.Token) {
+	switch res.Command {
+	case SQLCommandSelect:
+		res.ReadOnly = !hasForUpdateOrShare(toks) && !anyWritingCTE(res.CTEs)
+	case SQLCommandInsert, SQLCommandUpdate, SQLCommandDelete,
+		SQLCommandCreate, SQLCommandAlter, SQLCommandDrop, SQLCommandTruncate,
+		SQLCommandComment, SQLCommandGrant, SQLCommandRevoke:
+		res.HasSideEffects = true
+	case SQLCommandUnknown:
+		classifyUtility(res, toks)
+	}
+
+	if hasVolatileFuncCall(toks) {
+		res.HasSideEffects = true
+	}
+	res.RequiresTransaction = requiresTransaction(toks)
+}
+
+func classifyUtility(res *AnalysisResult, toks []sqltoken.Token) {
+	if len(toks) == 0 {
+		return
+	}
+	switch {
+	case toks[0].Is("SHOW"):
+		res.ReadOnly = true
+	case toks[0].Is("EXPLAIN"):
+		body := explainBody(toks)
+		if len(body) > 0 && (body[0].Is("SELECT") || body[0].Is("WITH")) {
+			res.ReadOnly = true
+		} else {
+			res.HasSideEffects = true
+		}
+	case toks[0].Is("SET") || toks[0].Is("RESET"):
+		res.HasSideEffects = true
+	case toks[0].Is("ALTER") && len(toks) > 1 && toks[1].Is("SYSTEM"):
+		res.HasSideEffects = true
+	}
+}
+
+// explainBody returns the tokens of the statement an EXPLAIN wraps, having
+// skipped over EXPLAIN's own optional "(options...)" and "ANALYZE".
+func explainBody(toks []sqltoken.Token) []sqltoken.Token {
+	i := 1
+	if i < len(toks) && toks[i].Kind == sqltoken.Punct && toks[i].Text == "(" {
+		if close := findMatchingParen(toks, i); close >= 0 {
+			i = close + 1
+		}
+	}
+	if i < len(toks) && toks[i].Is("ANALYZE") {
+		i++
+	}
+	if i <= len(toks) {
+		return toks[i:]
+	}
+	return nil
+}
+
+func hasForUpdateOrShare(toks []sqltoken.Token) bool {
+	for i := 0; i+1 < len(toks); i++ {
+		if toks[i].Is("FOR") && (toks[i+1].Is("UPDATE") || toks[i+1].Is("SHARE")) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolatileFuncCall(toks []sqltoken.Token) bool {
+	for i := 0; i+1 < len(toks); i++ {
+		if toks[i].Kind == sqltoken.Ident && volatileFuncNames[strings.ToLower(toks[i].Text)] &&
+			toks[i+1].Kind == sqltoken.Punct && toks[i+1].Text == "(" {
+			return true
+		}
+	}
+	return false
+}
+
+func requiresTransaction(toks []sqltoken.Token) bool {
+	if len(toks) == 0 {
+		return false
+	}
+	switch {
+	case toks[0].Is("VACUUM"):
+		return true
+	case toks[0].Is("REINDEX") && anyTokenIs(toks, "CONCURRENTLY"):
+		return true
+	case (toks[0].Is("CREATE") || toks[0].Is("DROP")) && len(toks) > 1 && toks[1].Is("INDEX") && anyTokenIs(toks, "CONCURRENTLY"):
+		return true
+	case (toks[0].Is("CREATE") || toks[0].Is("DROP")) && len(toks) > 1 && toks[1].Is("DATABASE"):
+		return true
+	case toks[0].Is("ALTER") && len(toks) > 1 && toks[1].Is("SYSTEM"):
+		return true
+	default:
+		return false
+	}
+}
+
+func anyTokenIs(toks []sqltoken.Token, kw string) bool {
+	for _, t := range toks {
+		if t.Is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyWritingCTE reports whether any of ctes' query text begins with a
+// writing command, so an otherwise plain-looking SELECT that reads from a
+// "WITH deleted AS (DELETE FROM ... RETURNING *)" CTE isn't misclassified
+// as read-only.
+func anyWritingCTE(ctes []CTE) bool {
+	for _, c := range ctes {
+		toks, err := sqltoken.Lex(c.Query)
+		if err != nil {
+			continue
+		}
+		toks = sqltoken.NonTrivia(toks)
+		if len(toks) == 0 {
+			continue
+		}
+		if toks[0].Is("INSERT") || toks[0].Is("UPDATE") || toks[0].Is("DELETE") || toks[0].Is("MERGE") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package analysis
+
+import "testing"
+
+func TestClassifyPlainSelectIsReadOnly(t *testing.T) {
+	res, err := AnalyzeSQL("SELECT id FROM orders WHERE status = 'open'")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.ReadOnly || res.HasSideEffects {
+		t.Fatalf("expected a plain SELECT to be read-only, got %+v", res)
+	}
+}
+
+func TestClassifySelectForUpdateIsNotReadOnly(t *testing.T) {
+	res, err := AnalyzeSQL("SELECT id FROM orders WHERE id = 1 FOR UPDATE")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.ReadOnly {
+		t.Fatalf("expected SELECT ... FOR UPDATE to not be read-only")
+	}
+}
+
+// TestClassifyIgnoresKeywordsInsideStringLiterals is the regression case the
+// reviewer flagged: classify must look at the token stream, not regex the
+// raw source, so a string literal that happens to contain "FOR UPDATE"
+// can't flip ReadOnly.
+func TestClassifyIgnoresKeywordsInsideStringLiterals(t *testing.T) {
+	res, err := AnalyzeSQL(`SELECT id FROM orders WHERE note = 'please FOR UPDATE this row'`)
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.ReadOnly {
+		t.Fatalf("expected ReadOnly: a string literal mentioning FOR UPDATE is not a real locking clause")
+	}
+}
+
+// TestClassifyIgnoresKeywordsInsideComments covers the same false-positive
+// risk for a volatile function name mentioned only inside a comment.
+func TestClassifyIgnoresKeywordsInsideComments(t *testing.T) {
+	res, err := AnalyzeSQL("SELECT id FROM orders -- calls nextval(foo) elsewhere\n")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.HasSideEffects {
+		t.Fatalf("expected a comment mentioning nextval() to not mark the statement as having side effects")
+	}
+}
+
+func TestClassifyVolatileFunctionCallHasSideEffects(t *testing.T) {
+	res, err := AnalyzeSQL("SELECT nextval('orders_id_seq')")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.HasSideEffects {
+		t.Fatalf("expected nextval() call to mark the statement as having side effects")
+	}
+}
+
+func TestClassifyDMLHasSideEffects(t *testing.T) {
+	res, err := AnalyzeSQL("DELETE FROM orders WHERE id = 1")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.HasSideEffects || res.ReadOnly {
+		t.Fatalf("expected DELETE to have side effects and not be read-only, got %+v", res)
+	}
+}
+
+func TestClassifyShowIsReadOnly(t *testing.T) {
+	res, err := AnalyzeSQL("SHOW client_min_messages")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.ReadOnly {
+		t.Fatalf("expected SHOW to be read-only, got %+v", res)
+	}
+}
+
+func TestClassifySetHasSideEffects(t *testing.T) {
+	res, err := AnalyzeSQL("SET client_min_messages = warning")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.HasSideEffects {
+		t.Fatalf("expected SET to have side effects, got %+v", res)
+	}
+}
+
+func TestClassifyVacuumRequiresNoImplicitTransaction(t *testing.T) {
+	res, err := AnalyzeSQL("VACUUM orders")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if !res.RequiresTransaction {
+		t.Fatalf("expected VACUUM to require running outside an implicit transaction, got %+v", res)
+	}
+}
+
+func TestClassifyWritingCTEIsNotReadOnly(t *testing.T) {
+	sql := `WITH moved AS (
+		DELETE FROM orders WHERE status = 'cancelled' RETURNING id
+	)
+	SELECT id FROM moved`
+
+	res, err := AnalyzeSQL(sql)
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.ReadOnly {
+		t.Fatalf("expected a SELECT over a writing CTE to not be read-only")
+	}
+}
@@ -0,0 +1,132 @@
+package analysis
+
+// SchemaObjectKind identifies the kind of object a DDL statement targets.
+type SchemaObjectKind string
+
+const (
+	SchemaObjectTable            SchemaObjectKind = "table"
+	SchemaObjectIndex            SchemaObjectKind = "index"
+	SchemaObjectView             SchemaObjectKind = "view"
+	SchemaObjectMaterializedView SchemaObjectKind = "materialized_view"
+	SchemaObjectSequence         SchemaObjectKind = "sequence"
+	SchemaObjectType             SchemaObjectKind = "type"
+	SchemaObjectSchema           SchemaObjectKind = "schema"
+	SchemaObjectFunction         SchemaObjectKind = "function"
+)
+
+// SchemaChange describes the object a CREATE, ALTER, DROP, TRUNCATE,
+// COMMENT, GRANT, or REVOKE statement affects.
+type SchemaChange struct {
+	Kind          SchemaObjectKind
+	QualifiedName string
+	IfExists      bool
+	IfNotExists   bool
+	Cascade       bool
+	Restrict      bool
+
+	// Table is populated for CREATE TABLE statements.
+	Table *TableDefinition
+
+	// AlterActions is populated for ALTER TABLE statements, one entry per
+	// clause in source order.
+	AlterActions []AlterAction
+}
+
+// ColumnDef describes one column of a CREATE TABLE statement, including any
+// constraints attached directly to it (e.g. "id serial PRIMARY KEY") rather
+// than spelled out as a separate table-level clause. Primary keys, unique
+// constraints, and foreign keys declared this way are also folded into
+// TableDefinition's own PrimaryKey/UniqueConstraints/ForeignKeys lists, so
+// callers only have to look in one place regardless of which form the SQL
+// used.
+type ColumnDef struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+
+	PrimaryKey bool
+	Unique     bool
+	References *ForeignKey
+	Check      *CheckConstraint
+}
+
+// UniqueConstraint describes a table-level UNIQUE (...) constraint.
+type UniqueConstraint struct {
+	Name    string
+	Columns []string
+}
+
+// ForeignKey describes a REFERENCES constraint.
+type ForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+	OnUpdate   string
+}
+
+// CheckConstraint describes a CHECK (...) constraint.
+type CheckConstraint struct {
+	Name       string
+	Expression string
+}
+
+// TableDefinition is the shape of a CREATE TABLE statement.
+type TableDefinition struct {
+	Columns           []ColumnDef
+	PrimaryKey        []string
+	UniqueConstraints []UniqueConstraint
+	ForeignKeys       []ForeignKey
+	CheckConstraints  []CheckConstraint
+	Partitioning      string
+	InheritsFrom      []string
+}
+
+// AlterActionKind identifies the kind of a single ALTER TABLE sub-action.
+type AlterActionKind string
+
+const (
+	AlterActionAddColumn       AlterActionKind = "add_column"
+	AlterActionDropColumn      AlterActionKind = "drop_column"
+	AlterActionAlterColumnType AlterActionKind = "alter_column_type"
+	AlterActionSetDefault      AlterActionKind = "set_default"
+	AlterActionAddConstraint   AlterActionKind = "add_constraint"
+	AlterActionDropConstraint  AlterActionKind = "drop_constraint"
+	AlterActionRenameTo        AlterActionKind = "rename_to"
+	AlterActionSetSchema       AlterActionKind = "set_schema"
+	AlterActionAttachPartition AlterActionKind = "attach_partition"
+)
+
+// AlterAction is a single typed sub-action of an ALTER TABLE statement,
+// e.g. the "ADD COLUMN foo int" in
+// "ALTER TABLE t ADD COLUMN foo int, DROP COLUMN bar".
+type AlterAction struct {
+	Kind AlterActionKind
+
+	// Column is populated for AddColumn, DropColumn, AlterColumnType, and
+	// SetDefault.
+	Column string
+	// Type is the new column type, for AddColumn and AlterColumnType.
+	Type string
+	// Default is the new default expression, for AddColumn and
+	// SetDefault.
+	Default string
+	// Constraint is the constraint definition or name, for AddConstraint
+	// and DropConstraint respectively.
+	Constraint string
+	// NewName is the target of RenameTo, SetSchema, and
+	// AttachPartition.
+	NewName string
+
+	// PrimaryKey, Unique, References, and Check carry constraints attached
+	// directly to the column in an AddColumn action, e.g.
+	// "ADD COLUMN id serial PRIMARY KEY" — see ColumnDef.
+	PrimaryKey bool
+	Unique     bool
+	References *ForeignKey
+	Check      *CheckConstraint
+
+	IfExists    bool
+	IfNotExists bool
+}
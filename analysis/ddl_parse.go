@@ -0,0 +1,501 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/tsingson/postgresparser/internal/sqltoken"
+)
+
+var columnModifierKeywords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true,
+	"UNIQUE": true, "REFERENCES": true, "CHECK": true,
+}
+
+// parseCreate parses a CREATE statement (toks[0] is "CREATE") and populates
+// res.Command and res.Schema.
+func parseCreate(res *AnalysisResult, toks []sqltoken.Token) error {
+	res.Command = SQLCommandCreate
+	i := 1
+	if i+1 < len(toks) && toks[i].Is("OR") && toks[i+1].Is("REPLACE") {
+		i += 2
+	}
+	if i < len(toks) && toks[i].Is("UNIQUE") {
+		i++
+	}
+	if i >= len(toks) {
+		return fmt.Errorf("analysis: incomplete CREATE statement")
+	}
+
+	switch {
+	case toks[i].Is("TABLE"):
+		return parseCreateTable(res, toks[i+1:])
+	case toks[i].Is("INDEX"):
+		return parseCreateIndex(res, toks[i+1:])
+	case toks[i].Is("MATERIALIZED") && i+1 < len(toks) && toks[i+1].Is("VIEW"):
+		return parseCreateSimple(res, SchemaObjectMaterializedView, toks[i+2:])
+	case toks[i].Is("VIEW"):
+		return parseCreateSimple(res, SchemaObjectView, toks[i+1:])
+	case toks[i].Is("SEQUENCE"):
+		return parseCreateSimple(res, SchemaObjectSequence, toks[i+1:])
+	case toks[i].Is("TYPE"):
+		return parseCreateSimple(res, SchemaObjectType, toks[i+1:])
+	case toks[i].Is("SCHEMA"):
+		return parseCreateSimple(res, SchemaObjectSchema, toks[i+1:])
+	case toks[i].Is("FUNCTION"):
+		return parseCreateSimple(res, SchemaObjectFunction, toks[i+1:])
+	default:
+		return fmt.Errorf("analysis: unsupported CREATE statement starting with %q", toks[i].Text)
+	}
+}
+
+func parseCreateSimple(res *AnalysisResult, kind SchemaObjectKind, toks []sqltoken.Token) error {
+	i := 0
+	ifNotExists := false
+	if i+2 < len(toks) && toks[i].Is("IF") && toks[i+1].Is("NOT") && toks[i+2].Is("EXISTS") {
+		ifNotExists = true
+		i += 3
+	}
+	name, _ := parseQualifiedName(toks[i:])
+	res.Schema = &SchemaChange{Kind: kind, QualifiedName: name, IfNotExists: ifNotExists}
+	return nil
+}
+
+func parseCreateIndex(res *AnalysisResult, toks []sqltoken.Token) error {
+	i := 0
+	if i < len(toks) && toks[i].Is("CONCURRENTLY") {
+		i++
+	}
+	ifNotExists := false
+	if i+2 < len(toks) && toks[i].Is("IF") && toks[i+1].Is("NOT") && toks[i+2].Is("EXISTS") {
+		ifNotExists = true
+		i += 3
+	}
+	name, _ := parseQualifiedName(toks[i:])
+	res.Schema = &SchemaChange{Kind: SchemaObjectIndex, QualifiedName: name, IfNotExists: ifNotExists}
+	return nil
+}
+
+func parseCreateTable(res *AnalysisResult, toks []sqltoken.Token) error {
+	i := 0
+	ifNotExists := false
+	if i+2 < len(toks) && toks[i].Is("IF") && toks[i+1].Is("NOT") && toks[i+2].Is("EXISTS") {
+		ifNotExists = true
+		i += 3
+	}
+	name, n := parseQualifiedName(toks[i:])
+	i += n
+	if i >= len(toks) || toks[i].Kind != sqltoken.Punct || toks[i].Text != "(" {
+		res.Schema = &SchemaChange{Kind: SchemaObjectTable, QualifiedName: name, IfNotExists: ifNotExists}
+		return nil
+	}
+	close := findMatchingParen(toks, i)
+	if close < 0 {
+		return fmt.Errorf("analysis: unmatched '(' in CREATE TABLE column list")
+	}
+	def := parseTableDefinition(toks[i+1 : close])
+	res.Schema = &SchemaChange{Kind: SchemaObjectTable, QualifiedName: name, IfNotExists: ifNotExists, Table: &def}
+	return nil
+}
+
+func parseTableDefinition(toks []sqltoken.Token) TableDefinition {
+	var def TableDefinition
+	for _, group := range sqltoken.SplitTopLevel(toks, ",") {
+		if len(group) == 0 {
+			continue
+		}
+		g := group
+		if g[0].Is("CONSTRAINT") && len(g) > 2 {
+			g = g[2:]
+		}
+		switch {
+		case len(g) > 1 && g[0].Is("PRIMARY") && g[1].Is("KEY"):
+			def.PrimaryKey = append(def.PrimaryKey, parenIdentList(g[2:])...)
+		case g[0].Is("UNIQUE"):
+			def.UniqueConstraints = append(def.UniqueConstraints, UniqueConstraint{Columns: parenIdentList(g[1:])})
+		case len(g) > 1 && g[0].Is("FOREIGN") && g[1].Is("KEY"):
+			def.ForeignKeys = append(def.ForeignKeys, parseForeignKey(g[2:]))
+		case g[0].Is("CHECK"):
+			def.CheckConstraints = append(def.CheckConstraints, CheckConstraint{Expression: sqltoken.Text(parenGroup(g[1:]))})
+		default:
+			col := parseColumnDef(group)
+			def.Columns = append(def.Columns, col)
+			foldColumnConstraints(&def, col)
+		}
+	}
+	return def
+}
+
+// foldColumnConstraints copies constraints parseColumnDef found attached
+// directly to col (e.g. "id serial PRIMARY KEY") into def's table-level
+// aggregates, so a caller inspecting def.PrimaryKey, def.UniqueConstraints,
+// etc. sees them the same way regardless of whether the SQL spelled the
+// constraint out as a separate table-level clause or inline on the column.
+func foldColumnConstraints(def *TableDefinition, col ColumnDef) {
+	if col.PrimaryKey {
+		def.PrimaryKey = append(def.PrimaryKey, col.Name)
+	}
+	if col.Unique {
+		def.UniqueConstraints = append(def.UniqueConstraints, UniqueConstraint{Columns: []string{col.Name}})
+	}
+	if col.References != nil {
+		def.ForeignKeys = append(def.ForeignKeys, *col.References)
+	}
+	if col.Check != nil {
+		def.CheckConstraints = append(def.CheckConstraints, *col.Check)
+	}
+}
+
+// parseColumnDef parses one column's definition, including constraints
+// attached directly to it — PRIMARY KEY, UNIQUE, REFERENCES, and CHECK —
+// rather than only the table-level clause forms of those constraints.
+func parseColumnDef(group []sqltoken.Token) ColumnDef {
+	col := ColumnDef{Nullable: true}
+	if len(group) == 0 {
+		return col
+	}
+	col.Name = group[0].Text
+	rest := group[1:]
+
+	typeEnd := len(rest)
+	for i, t := range rest {
+		if t.Kind == sqltoken.Ident && columnModifierKeywords[t.Upper()] {
+			typeEnd = i
+			break
+		}
+	}
+	col.Type = sqltoken.Text(rest[:typeEnd])
+
+	for i := typeEnd; i < len(rest); {
+		switch {
+		case rest[i].Is("NOT") && i+1 < len(rest) && rest[i+1].Is("NULL"):
+			col.Nullable = false
+			i += 2
+		case rest[i].Is("NULL"):
+			i++
+		case rest[i].Is("DEFAULT"):
+			end := nextModifierKeyword(rest, i+1)
+			col.Default = sqltoken.Text(rest[i+1 : end])
+			i = end
+		case rest[i].Is("PRIMARY") && i+1 < len(rest) && rest[i+1].Is("KEY"):
+			col.PrimaryKey = true
+			i += 2
+		case rest[i].Is("UNIQUE"):
+			col.Unique = true
+			i++
+		case rest[i].Is("REFERENCES"):
+			i++
+			name, n := parseQualifiedName(rest[i:])
+			i += n
+			refCols := parenIdentList(rest[i:])
+			if close := findMatchingParen(rest[i:], 0); close >= 0 {
+				i += close + 1
+			}
+			col.References = &ForeignKey{Columns: []string{col.Name}, RefTable: name, RefColumns: refCols}
+		case rest[i].Is("CHECK"):
+			i++
+			expr := sqltoken.Text(parenGroup(rest[i:]))
+			if close := findMatchingParen(rest[i:], 0); close >= 0 {
+				i += close + 1
+			}
+			col.Check = &CheckConstraint{Expression: expr}
+		default:
+			i++
+		}
+	}
+	return col
+}
+
+// nextModifierKeyword returns the index, at or after start, of the next
+// column modifier keyword in toks, or len(toks) if there is none — used to
+// find where a DEFAULT expression ends without swallowing a constraint that
+// follows it, e.g. the PRIMARY KEY in "id int DEFAULT 1 PRIMARY KEY".
+func nextModifierKeyword(toks []sqltoken.Token, start int) int {
+	for i := start; i < len(toks); i++ {
+		if toks[i].Kind == sqltoken.Ident && columnModifierKeywords[toks[i].Upper()] {
+			return i
+		}
+	}
+	return len(toks)
+}
+
+func parseForeignKey(toks []sqltoken.Token) ForeignKey {
+	var fk ForeignKey
+	fk.Columns = parenIdentList(toks)
+	i := 0
+	if len(toks) > 0 && toks[0].Kind == sqltoken.Punct && toks[0].Text == "(" {
+		if close := findMatchingParen(toks, 0); close >= 0 {
+			i = close + 1
+		}
+	}
+	if i < len(toks) && toks[i].Is("REFERENCES") {
+		i++
+	}
+	name, n := parseQualifiedName(toks[i:])
+	fk.RefTable = name
+	i += n
+	fk.RefColumns = parenIdentList(toks[i:])
+	return fk
+}
+
+func parenIdentList(toks []sqltoken.Token) []string {
+	g := parenGroup(toks)
+	var out []string
+	for _, part := range sqltoken.SplitTopLevel(g, ",") {
+		if len(part) > 0 {
+			out = append(out, part[0].Text)
+		}
+	}
+	return out
+}
+
+func parenGroup(toks []sqltoken.Token) []sqltoken.Token {
+	if len(toks) == 0 || toks[0].Kind != sqltoken.Punct || toks[0].Text != "(" {
+		return nil
+	}
+	close := findMatchingParen(toks, 0)
+	if close < 0 {
+		return nil
+	}
+	return toks[1:close]
+}
+
+func parseQualifiedName(toks []sqltoken.Token) (name string, consumed int) {
+	if len(toks) == 0 || toks[0].Kind != sqltoken.Ident {
+		return "", 0
+	}
+	name = toks[0].Text
+	i := 1
+	for i+1 < len(toks) && toks[i].Kind == sqltoken.Punct && toks[i].Text == "." && toks[i+1].Kind == sqltoken.Ident {
+		name += "." + toks[i+1].Text
+		i += 2
+	}
+	return name, i
+}
+
+// schemaObjectKindFrom identifies the object-kind keyword(s) toks begins
+// with (used by ALTER/DROP/COMMENT ON, which share the same set of object
+// kinds), returning how many tokens that keyword consumed.
+func schemaObjectKindFrom(toks []sqltoken.Token) (SchemaObjectKind, int) {
+	switch {
+	case len(toks) > 0 && toks[0].Is("TABLE"):
+		return SchemaObjectTable, 1
+	case len(toks) > 0 && toks[0].Is("INDEX"):
+		return SchemaObjectIndex, 1
+	case len(toks) > 0 && toks[0].Is("SEQUENCE"):
+		return SchemaObjectSequence, 1
+	case len(toks) > 0 && toks[0].Is("VIEW"):
+		return SchemaObjectView, 1
+	case len(toks) > 0 && toks[0].Is("TYPE"):
+		return SchemaObjectType, 1
+	case len(toks) > 0 && toks[0].Is("SCHEMA"):
+		return SchemaObjectSchema, 1
+	case len(toks) > 0 && toks[0].Is("FUNCTION"):
+		return SchemaObjectFunction, 1
+	case len(toks) > 1 && toks[0].Is("MATERIALIZED") && toks[1].Is("VIEW"):
+		return SchemaObjectMaterializedView, 2
+	default:
+		return "", 0
+	}
+}
+
+// parseAlterTable parses an ALTER statement (toks[0] is "ALTER"). Only
+// ALTER TABLE is broken down into per-clause AlterActions; other ALTER
+// targets (INDEX, SEQUENCE, VIEW, ...) just record the object affected.
+func parseAlterTable(res *AnalysisResult, toks []sqltoken.Token) error {
+	res.Command = SQLCommandAlter
+	if len(toks) < 2 || !toks[1].Is("TABLE") {
+		kind, consumed := schemaObjectKindFrom(toks[1:])
+		name, _ := parseQualifiedName(toks[1+consumed:])
+		res.Schema = &SchemaChange{Kind: kind, QualifiedName: name}
+		return nil
+	}
+
+	i := 2
+	ifExists := false
+	if i+1 < len(toks) && toks[i].Is("IF") && toks[i+1].Is("EXISTS") {
+		ifExists = true
+		i += 2
+	}
+	name, n := parseQualifiedName(toks[i:])
+	i += n
+
+	res.Schema = &SchemaChange{
+		Kind:          SchemaObjectTable,
+		QualifiedName: name,
+		IfExists:      ifExists,
+		AlterActions:  parseAlterActions(toks[i:]),
+	}
+	return nil
+}
+
+func parseAlterActions(toks []sqltoken.Token) []AlterAction {
+	var actions []AlterAction
+	for _, g := range sqltoken.SplitTopLevel(toks, ",") {
+		if len(g) == 0 {
+			continue
+		}
+		if a, ok := parseAlterAction(g); ok {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+func parseAlterAction(g []sqltoken.Token) (AlterAction, bool) {
+	switch {
+	case g[0].Is("ADD") && len(g) > 1 && g[1].Is("COLUMN"):
+		return parseAddColumn(g[2:]), true
+	case g[0].Is("ADD") && len(g) > 1 && g[1].Is("CONSTRAINT"):
+		return AlterAction{Kind: AlterActionAddConstraint, Constraint: sqltoken.Text(g[2:])}, true
+	case g[0].Is("ADD") && len(g) > 1 && (g[1].Is("PRIMARY") || g[1].Is("UNIQUE") || g[1].Is("FOREIGN") || g[1].Is("CHECK")):
+		return AlterAction{Kind: AlterActionAddConstraint, Constraint: sqltoken.Text(g[1:])}, true
+	case g[0].Is("ADD") && len(g) > 1 && g[1].Kind == sqltoken.Ident:
+		// ADD <column> <type> ...; the COLUMN keyword is optional.
+		return parseAddColumn(g[1:]), true
+	case g[0].Is("DROP") && len(g) > 1 && g[1].Is("COLUMN"):
+		return parseDropColumn(g[2:]), true
+	case g[0].Is("DROP") && len(g) > 1 && g[1].Is("CONSTRAINT"):
+		return parseDropConstraint(g[2:]), true
+	case g[0].Is("ALTER") && len(g) > 1 && g[1].Is("COLUMN"):
+		return parseAlterColumn(g[2:]), true
+	case g[0].Is("RENAME") && len(g) > 1 && g[1].Is("TO") && len(g) > 2:
+		return AlterAction{Kind: AlterActionRenameTo, NewName: g[2].Text}, true
+	case g[0].Is("SET") && len(g) > 1 && g[1].Is("SCHEMA") && len(g) > 2:
+		return AlterAction{Kind: AlterActionSetSchema, NewName: g[2].Text}, true
+	case g[0].Is("ATTACH") && len(g) > 1 && g[1].Is("PARTITION"):
+		name, _ := parseQualifiedName(g[2:])
+		return AlterAction{Kind: AlterActionAttachPartition, NewName: name}, true
+	}
+	return AlterAction{}, false
+}
+
+func parseAddColumn(g []sqltoken.Token) AlterAction {
+	ifNotExists := false
+	if len(g) > 2 && g[0].Is("IF") && g[1].Is("NOT") && g[2].Is("EXISTS") {
+		ifNotExists = true
+		g = g[3:]
+	}
+	col := parseColumnDef(g)
+	return AlterAction{
+		Kind:        AlterActionAddColumn,
+		Column:      col.Name,
+		Type:        col.Type,
+		Default:     col.Default,
+		IfNotExists: ifNotExists,
+		PrimaryKey:  col.PrimaryKey,
+		Unique:      col.Unique,
+		References:  col.References,
+		Check:       col.Check,
+	}
+}
+
+func parseDropColumn(g []sqltoken.Token) AlterAction {
+	ifExists := false
+	if len(g) > 1 && g[0].Is("IF") && g[1].Is("EXISTS") {
+		ifExists = true
+		g = g[2:]
+	}
+	name := ""
+	if len(g) > 0 {
+		name = g[0].Text
+	}
+	return AlterAction{Kind: AlterActionDropColumn, Column: name, IfExists: ifExists}
+}
+
+func parseDropConstraint(g []sqltoken.Token) AlterAction {
+	ifExists := false
+	if len(g) > 1 && g[0].Is("IF") && g[1].Is("EXISTS") {
+		ifExists = true
+		g = g[2:]
+	}
+	name := ""
+	if len(g) > 0 {
+		name = g[0].Text
+	}
+	return AlterAction{Kind: AlterActionDropConstraint, Constraint: name, IfExists: ifExists}
+}
+
+func parseAlterColumn(g []sqltoken.Token) AlterAction {
+	if len(g) == 0 {
+		return AlterAction{Kind: AlterActionAlterColumnType}
+	}
+	name := g[0].Text
+	rest := g[1:]
+	if len(rest) > 1 && rest[0].Is("SET") && rest[1].Is("DEFAULT") {
+		return AlterAction{Kind: AlterActionSetDefault, Column: name, Default: sqltoken.Text(rest[2:])}
+	}
+	if len(rest) > 0 && rest[0].Is("TYPE") {
+		return AlterAction{Kind: AlterActionAlterColumnType, Column: name, Type: sqltoken.Text(rest[1:])}
+	}
+	return AlterAction{Kind: AlterActionAlterColumnType, Column: name}
+}
+
+// parseDrop parses a DROP statement (toks[0] is "DROP").
+func parseDrop(res *AnalysisResult, toks []sqltoken.Token) error {
+	res.Command = SQLCommandDrop
+	kind, consumed := schemaObjectKindFrom(toks[1:])
+	i := 1 + consumed
+
+	ifExists := false
+	if i+1 < len(toks) && toks[i].Is("IF") && toks[i+1].Is("EXISTS") {
+		ifExists = true
+		i += 2
+	}
+	name, n := parseQualifiedName(toks[i:])
+	i += n
+
+	cascade, restrict := false, false
+	if i < len(toks) {
+		switch {
+		case toks[i].Is("CASCADE"):
+			cascade = true
+		case toks[i].Is("RESTRICT"):
+			restrict = true
+		}
+	}
+	res.Schema = &SchemaChange{Kind: kind, QualifiedName: name, IfExists: ifExists, Cascade: cascade, Restrict: restrict}
+	return nil
+}
+
+// parseTruncate parses a TRUNCATE statement (toks[0] is "TRUNCATE").
+func parseTruncate(res *AnalysisResult, toks []sqltoken.Token) {
+	res.Command = SQLCommandTruncate
+	i := 1
+	if i < len(toks) && toks[i].Is("TABLE") {
+		i++
+	}
+	name, _ := parseQualifiedName(toks[i:])
+	res.Schema = &SchemaChange{Kind: SchemaObjectTable, QualifiedName: name}
+}
+
+// parseComment parses a COMMENT ON statement (toks[0] is "COMMENT").
+func parseComment(res *AnalysisResult, toks []sqltoken.Token) {
+	res.Command = SQLCommandComment
+	i := 1
+	if i < len(toks) && toks[i].Is("ON") {
+		i++
+	}
+	kind, consumed := schemaObjectKindFrom(toks[i:])
+	i += consumed
+	name, _ := parseQualifiedName(toks[i:])
+	res.Schema = &SchemaChange{Kind: kind, QualifiedName: name}
+}
+
+// parseGrantRevoke parses a GRANT or REVOKE statement, recording the object
+// named after its ON clause.
+func parseGrantRevoke(res *AnalysisResult, toks []sqltoken.Token, command SQLCommand) {
+	res.Command = command
+	onIdx := sqltoken.FindKeyword(toks, "ON")
+	if onIdx < 0 {
+		return
+	}
+	i := onIdx + 1
+	kind, consumed := schemaObjectKindFrom(toks[i:])
+	if kind == "" {
+		kind = SchemaObjectTable
+	} else {
+		i += consumed
+	}
+	name, _ := parseQualifiedName(toks[i:])
+	res.Schema = &SchemaChange{Kind: kind, QualifiedName: name}
+}
@@ -0,0 +1,163 @@
+package analysis
+
+import "testing"
+
+func TestAnalyzeSQLCreateTable(t *testing.T) {
+	sql := `CREATE TABLE IF NOT EXISTS orders (
+		id BIGINT NOT NULL,
+		customer_id BIGINT NOT NULL,
+		status TEXT DEFAULT 'open',
+		PRIMARY KEY (id),
+		FOREIGN KEY (customer_id) REFERENCES customers (id)
+	)`
+
+	res, err := AnalyzeSQL(sql)
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.Command != SQLCommandCreate {
+		t.Fatalf("expected CREATE command, got %s", res.Command)
+	}
+	if res.Schema == nil {
+		t.Fatalf("expected Schema to be populated")
+	}
+	if res.Schema.Kind != SchemaObjectTable || res.Schema.QualifiedName != "orders" || !res.Schema.IfNotExists {
+		t.Fatalf("unexpected schema change: %+v", res.Schema)
+	}
+	if res.Schema.Table == nil {
+		t.Fatalf("expected table definition")
+	}
+	def := res.Schema.Table
+	if len(def.Columns) != 3 {
+		t.Fatalf("expected 3 columns, got %+v", def.Columns)
+	}
+	if def.Columns[0].Name != "id" || def.Columns[0].Nullable {
+		t.Fatalf("unexpected id column: %+v", def.Columns[0])
+	}
+	if def.Columns[2].Name != "status" || def.Columns[2].Default != "'open'" {
+		t.Fatalf("unexpected status column: %+v", def.Columns[2])
+	}
+	if len(def.PrimaryKey) != 1 || def.PrimaryKey[0] != "id" {
+		t.Fatalf("unexpected primary key: %+v", def.PrimaryKey)
+	}
+	if len(def.ForeignKeys) != 1 || def.ForeignKeys[0].RefTable != "customers" {
+		t.Fatalf("unexpected foreign keys: %+v", def.ForeignKeys)
+	}
+}
+
+func TestAnalyzeSQLCreateTableInlineColumnConstraints(t *testing.T) {
+	sql := `CREATE TABLE orders (
+		id serial PRIMARY KEY,
+		email text UNIQUE,
+		customer_id BIGINT REFERENCES customers (id),
+		status TEXT CHECK (status <> '')
+	)`
+
+	res, err := AnalyzeSQL(sql)
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	def := res.Schema.Table
+	if def == nil {
+		t.Fatalf("expected table definition")
+	}
+
+	if !def.Columns[0].PrimaryKey {
+		t.Fatalf("expected id column to carry PrimaryKey, got %+v", def.Columns[0])
+	}
+	if len(def.PrimaryKey) != 1 || def.PrimaryKey[0] != "id" {
+		t.Fatalf("expected inline PRIMARY KEY to be folded into table-level PrimaryKey, got %+v", def.PrimaryKey)
+	}
+
+	if !def.Columns[1].Unique {
+		t.Fatalf("expected email column to carry Unique, got %+v", def.Columns[1])
+	}
+	if len(def.UniqueConstraints) != 1 || len(def.UniqueConstraints[0].Columns) != 1 || def.UniqueConstraints[0].Columns[0] != "email" {
+		t.Fatalf("expected inline UNIQUE to be folded into table-level UniqueConstraints, got %+v", def.UniqueConstraints)
+	}
+
+	if def.Columns[2].References == nil || def.Columns[2].References.RefTable != "customers" {
+		t.Fatalf("expected customer_id column to carry References, got %+v", def.Columns[2])
+	}
+	if len(def.ForeignKeys) != 1 || def.ForeignKeys[0].RefTable != "customers" || def.ForeignKeys[0].RefColumns[0] != "id" {
+		t.Fatalf("expected inline REFERENCES to be folded into table-level ForeignKeys, got %+v", def.ForeignKeys)
+	}
+
+	if def.Columns[3].Check == nil || def.Columns[3].Check.Expression != "status <> ''" {
+		t.Fatalf("expected status column to carry Check, got %+v", def.Columns[3])
+	}
+	if len(def.CheckConstraints) != 1 || def.CheckConstraints[0].Expression != "status <> ''" {
+		t.Fatalf("expected inline CHECK to be folded into table-level CheckConstraints, got %+v", def.CheckConstraints)
+	}
+}
+
+func TestAnalyzeSQLAlterTableActions(t *testing.T) {
+	sql := `ALTER TABLE orders ADD COLUMN shipped_at timestamp, DROP COLUMN legacy_flag, RENAME TO purchase_orders`
+
+	res, err := AnalyzeSQL(sql)
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.Command != SQLCommandAlter {
+		t.Fatalf("expected ALTER command, got %s", res.Command)
+	}
+	if res.Schema == nil || res.Schema.QualifiedName != "orders" {
+		t.Fatalf("unexpected schema change: %+v", res.Schema)
+	}
+	if len(res.Schema.AlterActions) != 3 {
+		t.Fatalf("expected 3 alter actions, got %+v", res.Schema.AlterActions)
+	}
+	if res.Schema.AlterActions[0].Kind != AlterActionAddColumn || res.Schema.AlterActions[0].Column != "shipped_at" {
+		t.Fatalf("unexpected first action: %+v", res.Schema.AlterActions[0])
+	}
+	if res.Schema.AlterActions[1].Kind != AlterActionDropColumn || res.Schema.AlterActions[1].Column != "legacy_flag" {
+		t.Fatalf("unexpected second action: %+v", res.Schema.AlterActions[1])
+	}
+	if res.Schema.AlterActions[2].Kind != AlterActionRenameTo || res.Schema.AlterActions[2].NewName != "purchase_orders" {
+		t.Fatalf("unexpected third action: %+v", res.Schema.AlterActions[2])
+	}
+}
+
+func TestAnalyzeSQLDropTableCascade(t *testing.T) {
+	res, err := AnalyzeSQL("DROP TABLE IF EXISTS orders CASCADE")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.Command != SQLCommandDrop {
+		t.Fatalf("expected DROP command, got %s", res.Command)
+	}
+	if res.Schema == nil || res.Schema.QualifiedName != "orders" || !res.Schema.IfExists || !res.Schema.Cascade {
+		t.Fatalf("unexpected schema change: %+v", res.Schema)
+	}
+}
+
+func TestAnalyzeSQLTruncateAndComment(t *testing.T) {
+	res, err := AnalyzeSQL("TRUNCATE TABLE orders")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.Command != SQLCommandTruncate || res.Schema == nil || res.Schema.QualifiedName != "orders" {
+		t.Fatalf("unexpected truncate result: %+v", res)
+	}
+
+	res, err = AnalyzeSQL("COMMENT ON TABLE orders IS 'purchase orders'")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.Command != SQLCommandComment || res.Schema == nil || res.Schema.QualifiedName != "orders" {
+		t.Fatalf("unexpected comment result: %+v", res)
+	}
+}
+
+func TestAnalyzeSQLGrantRecordsTargetObject(t *testing.T) {
+	res, err := AnalyzeSQL("GRANT SELECT, INSERT ON orders TO reporting")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if res.Command != SQLCommandGrant {
+		t.Fatalf("expected GRANT command, got %s", res.Command)
+	}
+	if res.Schema == nil || res.Schema.QualifiedName != "orders" {
+		t.Fatalf("unexpected schema change: %+v", res.Schema)
+	}
+}
@@ -0,0 +1,65 @@
+// Package normalize produces a canonical form of a SQL statement, along
+// with a stable digest of that form, suitable for use as a cache key or a
+// grouping key when aggregating query-level metrics — similar in spirit to
+// the statement digest TiDB's bindinfo and statement-summary subsystems
+// compute over normalized SQL text.
+package normalize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/tsingson/postgresparser/analysis"
+)
+
+var (
+	lineComment  = regexp.MustCompile(`--[^\n]*`)
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	whitespace   = regexp.MustCompile(`\s+`)
+	stringLit    = regexp.MustCompile(`(?i)E?'(?:[^']|'')*'`)
+	numberLit    = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	boolLit      = regexp.MustCompile(`(?i)\bTRUE\b|\bFALSE\b`)
+	inList       = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	keyword      = regexp.MustCompile(`(?i)\b(SELECT|FROM|WHERE|INSERT|INTO|VALUES|UPDATE|SET|DELETE|JOIN|LEFT|RIGHT|INNER|OUTER|ON|GROUP|BY|ORDER|HAVING|LIMIT|OFFSET|AND|OR|NOT|NULL|IN|IS|AS|DISTINCT|UNION|ALL|CASE|WHEN|THEN|ELSE|END|RETURNING|CONFLICT|DO|NOTHING|EXISTS)\b`)
+)
+
+// Normalize produces a canonical form of sql suitable for grouping
+// structurally identical queries together, and a stable digest of that
+// form. Normalization lowercases the common keywords listed in keyword,
+// collapses whitespace, replaces numeric/string/boolean literals with "?",
+// collapses "IN (?, ?, ...)" lists to "IN (...)", and strips trailing
+// semicolons and comments. Identifier casing and quoting are left
+// untouched outside double quotes; the rewriting works on the raw text, so
+// a double-quoted identifier is not protected from keyword lowercasing or
+// literal replacement.
+func Normalize(sql string) (normalized string, digest string, err error) {
+	s := blockComment.ReplaceAllString(sql, "")
+	s = lineComment.ReplaceAllString(s, "")
+	s = strings.TrimRight(strings.TrimSpace(s), "; \t\r\n")
+	s = stringLit.ReplaceAllString(s, "?")
+	s = numberLit.ReplaceAllString(s, "?")
+	s = boolLit.ReplaceAllString(s, "?")
+	s = whitespace.ReplaceAllString(s, " ")
+	s = keyword.ReplaceAllStringFunc(s, strings.ToLower)
+	s = inList.ReplaceAllString(s, "in (...)")
+	normalized = strings.TrimSpace(s)
+	digest = Digest(normalized)
+	return normalized, digest, nil
+}
+
+// Digest returns the stable fingerprint of an already-normalized query: the
+// hex-encoded first 16 bytes of its SHA-256 hash.
+func Digest(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NormalizeAnalysis normalizes the statement res was built from, reusing
+// res.Source so the caller doesn't have to re-parse the query just to
+// normalize it.
+func NormalizeAnalysis(res *analysis.AnalysisResult) (normalized string, digest string) {
+	normalized, digest, _ = Normalize(res.Source)
+	return normalized, digest
+}
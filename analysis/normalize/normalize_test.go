@@ -0,0 +1,53 @@
+package normalize
+
+import "testing"
+
+func TestNormalizeStripsCommentsAndLiterals(t *testing.T) {
+	sql := `SELECT * FROM orders -- trailing comment
+WHERE status = 'open' AND total > 100;`
+
+	normalized, digest, err := Normalize(sql)
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	want := "select * from orders where status = ? and total > ?"
+	if normalized != want {
+		t.Fatalf("normalized = %q, want %q", normalized, want)
+	}
+	if digest == "" {
+		t.Fatalf("expected a non-empty digest")
+	}
+}
+
+func TestNormalizeCollapsesInList(t *testing.T) {
+	normalized, _, err := Normalize("SELECT * FROM t WHERE id IN (1, 2, 3)")
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	want := "select * from t where id in (...)"
+	if normalized != want {
+		t.Fatalf("normalized = %q, want %q", normalized, want)
+	}
+}
+
+func TestNormalizeIsStableAcrossLiteralValues(t *testing.T) {
+	_, d1, err := Normalize("SELECT * FROM orders WHERE id = 1")
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	_, d2, err := Normalize("SELECT * FROM orders WHERE id = 2")
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if d1 != d2 {
+		t.Fatalf("expected structurally identical queries to share a digest, got %q and %q", d1, d2)
+	}
+}
+
+func TestDigestDiffersForDifferentNormalizedText(t *testing.T) {
+	d1 := Digest("select * from orders")
+	d2 := Digest("select * from customers")
+	if d1 == d2 {
+		t.Fatalf("expected different normalized text to produce different digests")
+	}
+}
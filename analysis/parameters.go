@@ -0,0 +1,227 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tsingson/postgresparser/internal/sqltoken"
+)
+
+// CanonicalizePlaceholders finds every placeholder in sql — "?", "$N", or
+// ":name" — in source order and returns the statement rewritten to use "?"
+// throughout, along with one Parameter per placeholder carrying its
+// canonical Ordinal, Name (for ":name"), and source Location. A repeated
+// ":name" placeholder is assigned the ordinal of its first occurrence, and
+// a "$N" placeholder is assigned ordinal N regardless of position.
+//
+// Placeholders are found by lexing sql rather than scanning its raw text, so
+// a "?" or ":name"-shaped run of characters inside a string literal or
+// comment is never mistaken for a real placeholder.
+func CanonicalizePlaceholders(sql string) (canonical string, params []Parameter) {
+	toks, err := sqltoken.Lex(sql)
+	if err != nil {
+		return sql, nil
+	}
+	toks = sqltoken.NonTrivia(toks)
+
+	seenNames := map[string]int{}
+	next := 1
+	var b strings.Builder
+	last := 0
+	for _, t := range toks {
+		if !isPlaceholderToken(t) {
+			continue
+		}
+
+		b.WriteString(sql[last:t.Pos])
+		b.WriteString("?")
+		last = t.Pos + len(t.Raw)
+		loc := Location{Line: t.Line, Col: t.Col}
+
+		switch {
+		case t.Text[0] == '$':
+			n, err := strconv.Atoi(t.Text[1:])
+			if err != nil {
+				continue
+			}
+			if n >= next {
+				next = n + 1
+			}
+			params = append(params, Parameter{Ordinal: n, Location: loc})
+		case t.Text[0] == ':':
+			name := t.Text[1:]
+			ord, ok := seenNames[name]
+			if !ok {
+				ord = next
+				next++
+				seenNames[name] = ord
+			}
+			params = append(params, Parameter{Ordinal: ord, Name: name, Location: loc})
+		default:
+			params = append(params, Parameter{Ordinal: next, Location: loc})
+			next++
+		}
+	}
+	b.WriteString(sql[last:])
+	return b.String(), params
+}
+
+// isPlaceholderToken reports whether t is one of the three placeholder
+// spellings this parser accepts. The lexer already tells "$1" (a positional
+// parameter) apart from "::" (a cast operator) and ":name" (a named
+// parameter), so unlike a raw-text regex this never needs to special-case
+// the second colon of a cast.
+func isPlaceholderToken(t sqltoken.Token) bool {
+	if t.Kind != sqltoken.Punct {
+		return false
+	}
+	switch {
+	case t.Text == "?":
+		return true
+	case len(t.Text) > 1 && t.Text[0] == '$':
+		for _, c := range t.Text[1:] {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+		return true
+	case len(t.Text) > 1 && t.Text[0] == ':' && t.Text != "::":
+		return true
+	default:
+		return false
+	}
+}
+
+// inferParameterTypes fills in Source for each of res's already-positioned
+// Parameters, plus InferredType for an explicit cast and Name (the target
+// column) for an INSERT value, working from toks (the token stream
+// AnalyzeSQL already lexed for res.Source) rather than raw source text — so
+// a keyword-shaped word sitting inside an earlier string literal or comment
+// (e.g. a CASE WHEN branch comparing against the string 'please SET
+// properly') can't be mistaken for a real clause. It looks, in order of
+// confidence, for an explicit "::type" cast, a "SET column = ?" clause (so
+// an UPDATE's or upsert's assignment isn't mistaken for a plain comparison),
+// a position inside an INSERT ... VALUES (...) list (matched against
+// InsertColumns by position), a "column <op> ?" comparison, and a scalar
+// function call.
+func (res *AnalysisResult) inferParameterTypes(toks []sqltoken.Token) {
+	depths := sqltoken.Depths(toks)
+	valuesOpen, valuesClose, hasValues := insertValuesSpan(res, toks)
+
+	for i := range res.Parameters {
+		p := &res.Parameters[i]
+		idx := tokenIndexAt(toks, p.Location)
+
+		switch {
+		case idx < 0:
+			p.Source = ParameterSourceUnknown
+		case idx+2 < len(toks) && toks[idx+1].Kind == sqltoken.Punct && toks[idx+1].Text == "::" && toks[idx+2].Kind == sqltoken.Ident:
+			p.Source = ParameterSourceCast
+			p.InferredType = strings.ToLower(toks[idx+2].Text)
+		case isInSetClause(toks, depths, idx):
+			p.Source = ParameterSourceUpdateSet
+		case res.Command == SQLCommandInsert && hasValues:
+			if col, ok := insertValueColumn(res, toks, valuesOpen, valuesClose, idx); ok {
+				p.Source = ParameterSourceInsertColumn
+				p.Name = col
+			} else {
+				p.Source = ParameterSourceUnknown
+			}
+		case idx > 0 && isComparisonOp(toks[idx-1]):
+			p.Source = ParameterSourceComparison
+		case idx >= 2 && toks[idx-1].Kind == sqltoken.Punct && toks[idx-1].Text == "(" && toks[idx-2].Kind == sqltoken.Ident:
+			p.Source = ParameterSourceFunctionArg
+		default:
+			p.Source = ParameterSourceUnknown
+		}
+	}
+}
+
+// tokenIndexAt returns the index into toks of the token starting at loc, or
+// -1 if none matches. Each placeholder's Location was recorded from the
+// same lex of the same source, so a Line/Col match identifies the exact
+// token.
+func tokenIndexAt(toks []sqltoken.Token, loc Location) int {
+	for i, t := range toks {
+		if t.Line == loc.Line && t.Col == loc.Col {
+			return i
+		}
+	}
+	return -1
+}
+
+func isComparisonOp(t sqltoken.Token) bool {
+	if t.Kind != sqltoken.Punct {
+		return false
+	}
+	switch t.Text {
+	case "=", "<>", "!=", "<=", ">=", "<", ">":
+		return true
+	default:
+		return false
+	}
+}
+
+// insertValuesSpan locates the token indices of the "(" and ")" bracketing
+// an INSERT statement's single-row VALUES (...) list, so callers can test
+// whether a given token index falls inside it.
+func insertValuesSpan(res *AnalysisResult, toks []sqltoken.Token) (open, close int, ok bool) {
+	if res.Command != SQLCommandInsert || len(res.InsertColumns) == 0 {
+		return 0, 0, false
+	}
+	valuesIdx := sqltoken.FindKeyword(toks, "VALUES")
+	if valuesIdx < 0 || valuesIdx+1 >= len(toks) || toks[valuesIdx+1].Kind != sqltoken.Punct || toks[valuesIdx+1].Text != "(" {
+		return 0, 0, false
+	}
+	open = valuesIdx + 1
+	close = findMatchingParen(toks, open)
+	if close < 0 {
+		return 0, 0, false
+	}
+	return open, close, true
+}
+
+// insertValueColumn returns the InsertColumns entry at the same top-level,
+// comma-separated position within (open, close) as the token at idx.
+func insertValueColumn(res *AnalysisResult, toks []sqltoken.Token, open, close, idx int) (string, bool) {
+	if idx <= open || idx >= close {
+		return "", false
+	}
+	col := 0
+	depth := 0
+	for i := open + 1; i < idx; i++ {
+		switch {
+		case toks[i].Kind == sqltoken.Punct && toks[i].Text == "(":
+			depth++
+		case toks[i].Kind == sqltoken.Punct && toks[i].Text == ")":
+			depth--
+		case toks[i].Kind == sqltoken.Punct && toks[i].Text == "," && depth == 0:
+			col++
+		}
+	}
+	if col >= len(res.InsertColumns) {
+		return "", false
+	}
+	return res.InsertColumns[col], true
+}
+
+// isInSetClause reports whether the token at idx sits after a top-level SET
+// keyword with no intervening top-level WHERE or RETURNING, i.e. inside an
+// UPDATE ... SET or INSERT ... ON CONFLICT DO UPDATE SET clause.
+func isInSetClause(toks []sqltoken.Token, depths []int, idx int) bool {
+	setPos := -1
+	for i := 0; i < idx; i++ {
+		if depths[i] == 0 && toks[i].Is("SET") {
+			setPos = i
+		}
+	}
+	if setPos < 0 {
+		return false
+	}
+	for i := setPos + 1; i < idx; i++ {
+		if depths[i] == 0 && (toks[i].Is("WHERE") || toks[i].Is("RETURNING")) {
+			return false
+		}
+	}
+	return true
+}
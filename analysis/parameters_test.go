@@ -0,0 +1,90 @@
+package analysis
+
+import "testing"
+
+func TestCanonicalizePlaceholdersMixedDialects(t *testing.T) {
+	canonical, params := CanonicalizePlaceholders("SELECT * FROM t WHERE a = $1 AND b = :name AND c = ? AND d = :name")
+	want := "SELECT * FROM t WHERE a = ? AND b = ? AND c = ? AND d = ?"
+	if canonical != want {
+		t.Fatalf("canonical = %q, want %q", canonical, want)
+	}
+	if len(params) != 4 {
+		t.Fatalf("expected 4 parameters, got %+v", params)
+	}
+	if params[0].Ordinal != 1 {
+		t.Fatalf("expected $1 to keep ordinal 1, got %+v", params[0])
+	}
+	if params[1].Name != "name" || params[3].Name != "name" || params[1].Ordinal != params[3].Ordinal {
+		t.Fatalf("expected repeated :name to share an ordinal, got %+v and %+v", params[1], params[3])
+	}
+}
+
+func TestInferParameterTypesCastAndComparison(t *testing.T) {
+	res, err := AnalyzeSQL("SELECT id FROM orders WHERE created_at > ?::timestamp AND status = ?")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if len(res.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %+v", res.Parameters)
+	}
+	if res.Parameters[0].Source != ParameterSourceCast || res.Parameters[0].InferredType != "timestamp" {
+		t.Fatalf("expected first parameter to be cast-inferred as timestamp, got %+v", res.Parameters[0])
+	}
+	if res.Parameters[1].Source != ParameterSourceComparison {
+		t.Fatalf("expected second parameter to be comparison-inferred, got %+v", res.Parameters[1])
+	}
+}
+
+// TestInferParameterTypesIgnoresKeywordsInsideStringLiterals is the
+// regression case the reviewer flagged: a word that looks like a clause
+// keyword but sits inside an earlier string literal (here, "SET" inside a
+// CASE WHEN branch's comparison value) must not be mistaken for a real SET
+// clause when classifying a later, unrelated parameter.
+func TestInferParameterTypesIgnoresKeywordsInsideStringLiterals(t *testing.T) {
+	res, err := AnalyzeSQL("SELECT CASE WHEN note = 'please SET properly' THEN 1 ELSE 0 END, fn(?) FROM t")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if len(res.Parameters) != 1 {
+		t.Fatalf("expected 1 parameter, got %+v", res.Parameters)
+	}
+	if res.Parameters[0].Source != ParameterSourceFunctionArg {
+		t.Fatalf("expected fn(?)'s parameter to be function-arg-inferred, got %+v", res.Parameters[0])
+	}
+}
+
+func TestInferParameterTypesInsertColumn(t *testing.T) {
+	res, err := AnalyzeSQL("INSERT INTO users (id, email) VALUES (?, ?)")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if len(res.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %+v", res.Parameters)
+	}
+	if res.Parameters[0].Source != ParameterSourceInsertColumn || res.Parameters[0].Name != "id" {
+		t.Fatalf("unexpected first parameter: %+v", res.Parameters[0])
+	}
+	if res.Parameters[1].Source != ParameterSourceInsertColumn || res.Parameters[1].Name != "email" {
+		t.Fatalf("unexpected second parameter: %+v", res.Parameters[1])
+	}
+}
+
+// TestInferParameterTypesSetClauseNotComparison ensures an UPDATE's "SET
+// col = ?" assignment is classified as ParameterSourceUpdateSet rather than
+// ParameterSourceComparison, since "=" alone can't tell an assignment from
+// a WHERE-clause equality check.
+func TestInferParameterTypesSetClauseNotComparison(t *testing.T) {
+	res, err := AnalyzeSQL("UPDATE t SET a = ? WHERE b = ?")
+	if err != nil {
+		t.Fatalf("AnalyzeSQL failed: %v", err)
+	}
+	if len(res.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %+v", res.Parameters)
+	}
+	if res.Parameters[0].Source != ParameterSourceUpdateSet {
+		t.Fatalf("expected SET assignment to be update_set-inferred, got %+v", res.Parameters[0])
+	}
+	if res.Parameters[1].Source != ParameterSourceComparison {
+		t.Fatalf("expected WHERE clause to be comparison-inferred, got %+v", res.Parameters[1])
+	}
+}
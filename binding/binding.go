@@ -0,0 +1,241 @@
+// Package binding lets callers register (originalPattern, replacementSQL)
+// pairs, keyed by the normalized digest of the original query, and rewrite
+// incoming queries that match — mirroring TiDB's global/session
+// "CREATE BINDING FOR ... USING ..." feature. It turns the parser into a
+// building block for a lightweight query-rewrite proxy.
+package binding
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/tsingson/postgresparser/analysis"
+	"github.com/tsingson/postgresparser/analysis/normalize"
+)
+
+// Binding is a single registered rewrite rule. Original is normalized (for
+// readability alongside Digest); Replacement is kept verbatim so rewriting
+// never loses anything normalization would strip, such as a comment-based
+// optimizer hint.
+type Binding struct {
+	Digest      string
+	Original    string
+	Replacement string
+
+	// replLitSource maps the index of each literal in Replacement (in
+	// source order) to the index, in Original's own literal list, of the
+	// literal it was copied from — or -1 if Replacement introduced that
+	// literal itself (e.g. an added "AND priority > 5" threshold). Match
+	// only ever substitutes a slot that has a source: that is how a
+	// fixed constant Replacement adds survives untouched instead of
+	// being overwritten by whichever incoming literal happens to reach
+	// that position.
+	replLitSource []int
+}
+
+// Manager holds a set of registered bindings. A Manager created via
+// NewSession overlays its parent: Match consults the session's own
+// bindings first, then falls back to the parent (typically the global
+// Manager), without mutating it.
+type Manager struct {
+	mu       sync.RWMutex
+	bindings map[string]Binding
+	parent   *Manager
+}
+
+// NewManager returns an empty, top-level (global) binding Manager.
+func NewManager() *Manager {
+	return &Manager{bindings: make(map[string]Binding)}
+}
+
+// NewSession returns a Manager whose bindings overlay m.
+func (m *Manager) NewSession() *Manager {
+	return &Manager{bindings: make(map[string]Binding), parent: m}
+}
+
+// Add registers a rewrite from original to replacement. Both sides must
+// parse, describe the same SQL command, reference the same set of tables,
+// and project the same number of output columns, so a binding can never
+// silently change the shape of a query's result. Only original is
+// normalized for storage and digest lookup; replacement is kept verbatim
+// (see Binding).
+func (m *Manager) Add(original, replacement string) error {
+	origAnalysis, err := analysis.AnalyzeSQL(original)
+	if err != nil {
+		return fmt.Errorf("binding: original does not parse: %w", err)
+	}
+	replAnalysis, err := analysis.AnalyzeSQL(replacement)
+	if err != nil {
+		return fmt.Errorf("binding: replacement does not parse: %w", err)
+	}
+	if origAnalysis.Command != replAnalysis.Command {
+		return fmt.Errorf("binding: command mismatch: original is %s, replacement is %s", origAnalysis.Command, replAnalysis.Command)
+	}
+	if !sameTables(origAnalysis.Tables, replAnalysis.Tables) {
+		return fmt.Errorf("binding: replacement references a different table set than original")
+	}
+	if len(origAnalysis.Columns) != len(replAnalysis.Columns) {
+		return fmt.Errorf("binding: replacement projects %d columns, original projects %d", len(replAnalysis.Columns), len(origAnalysis.Columns))
+	}
+
+	normOriginal, digest, err := normalize.Normalize(original)
+	if err != nil {
+		return err
+	}
+	// Only the original is normalized: the digest (and the normalized
+	// Original stored for display) come from it, but the replacement is
+	// kept verbatim. Normalizing the replacement would strip comments,
+	// which is how optimizer hints like "/*+ IndexScan(t) */" are spelled
+	// — Match must be able to re-emit them untouched.
+	if _, _, err := normalize.Normalize(replacement); err != nil {
+		return err
+	}
+
+	origLits := literalPattern.FindAllString(original, -1)
+	replLits := literalPattern.FindAllString(replacement, -1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bindings[digest] = Binding{
+		Digest:        digest,
+		Original:      normOriginal,
+		Replacement:   replacement,
+		replLitSource: alignLiteralSources(origLits, replLits),
+	}
+	return nil
+}
+
+// Match normalizes sql, looks up a binding for its digest (checking m
+// before falling back to its parent session, if any), and if found returns
+// the bound replacement with the literals it carried over from Original
+// swapped out for sql's literals at the same positions. Substituting
+// directly into the raw replacement text (rather than into a pre-normalized
+// copy) means anything Normalize would otherwise strip as a comment — most
+// importantly an optimizer hint such as "/*+ IndexScan(t) */" — survives
+// into the rewritten query. A literal Replacement introduced itself (with
+// no counterpart in Original) is left exactly as registered, so it can't be
+// clobbered by an unrelated incoming value.
+func (m *Manager) Match(sql string) (rewritten string, matched bool, err error) {
+	_, digest, err := normalize.Normalize(sql)
+	if err != nil {
+		return "", false, err
+	}
+
+	b, ok := m.lookup(digest)
+	if !ok {
+		return "", false, nil
+	}
+
+	literals := literalPattern.FindAllString(sql, -1)
+	i := 0
+	rewritten = literalPattern.ReplaceAllStringFunc(b.Replacement, func(orig string) string {
+		origIdx := -1
+		if i < len(b.replLitSource) {
+			origIdx = b.replLitSource[i]
+		}
+		i++
+		if origIdx < 0 || origIdx >= len(literals) {
+			return orig
+		}
+		return literals[origIdx]
+	})
+	return rewritten, true, nil
+}
+
+// alignLiteralSources computes, for each literal in replLits (in order),
+// the index of the literal in origLits it corresponds to, by finding the
+// longest common subsequence between the two value sequences. Literals
+// Replacement added beyond what Original had (e.g. an extra threshold
+// clause) fall out of the subsequence and are reported as -1.
+func alignLiteralSources(origLits, replLits []string) []int {
+	n, m := len(origLits), len(replLits)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if origLits[i] == replLits[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	source := make([]int, m)
+	for j := range source {
+		source[j] = -1
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case origLits[i] == replLits[j]:
+			source[j] = i
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return source
+}
+
+// Remove deletes the binding registered under digest on m, if any. It does
+// not affect a parent session.
+func (m *Manager) Remove(digest string) {
+	m.mu.Lock()
+	delete(m.bindings, digest)
+	m.mu.Unlock()
+}
+
+// List returns the bindings registered directly on m, not including any
+// inherited from a parent session.
+func (m *Manager) List() []Binding {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Binding, 0, len(m.bindings))
+	for _, b := range m.bindings {
+		out = append(out, b)
+	}
+	return out
+}
+
+func (m *Manager) lookup(digest string) (Binding, bool) {
+	m.mu.RLock()
+	b, ok := m.bindings[digest]
+	m.mu.RUnlock()
+	if ok {
+		return b, true
+	}
+	if m.parent != nil {
+		return m.parent.lookup(digest)
+	}
+	return Binding{}, false
+}
+
+func sameTables(a, b []analysis.Table) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, t := range a {
+		counts[t.Name]++
+	}
+	for _, t := range b {
+		counts[t.Name]--
+	}
+	for _, c := range counts {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+var literalPattern = regexp.MustCompile(`(?i)E?'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
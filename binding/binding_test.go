@@ -0,0 +1,136 @@
+package binding
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddAndMatchRewritesQuery(t *testing.T) {
+	m := NewManager()
+	if err := m.Add("SELECT id FROM orders WHERE status = 'open'", "SELECT id FROM orders WHERE status = 'open' ORDER BY id"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	rewritten, matched, err := m.Match("SELECT id FROM orders WHERE status = 'shipped'")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if !matched {
+		t.Fatalf("expected a structurally identical query to match")
+	}
+	if !strings.Contains(rewritten, "'shipped'") {
+		t.Fatalf("expected the incoming literal to be substituted into the rewrite, got %q", rewritten)
+	}
+	if !strings.Contains(rewritten, "ORDER BY id") {
+		t.Fatalf("expected the replacement's extra clause to survive, got %q", rewritten)
+	}
+}
+
+// TestMatchDoesNotClobberReplacementsOwnLiteral is the regression case the
+// reviewer flagged: a fixed constant the replacement adds (here, the
+// "priority > 5" threshold) has no counterpart in the original query, so it
+// must survive Match untouched instead of being overwritten by whichever
+// incoming literal happens to land on its position.
+func TestMatchDoesNotClobberReplacementsOwnLiteral(t *testing.T) {
+	m := NewManager()
+	original := "SELECT id FROM orders WHERE status = 'open' AND region = 'east'"
+	replacement := "SELECT id FROM orders WHERE status = 'open' AND priority > 5 AND region = 'east'"
+	if err := m.Add(original, replacement); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	rewritten, matched, err := m.Match("SELECT id FROM orders WHERE status = 'shipped' AND region = 'west'")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if !matched {
+		t.Fatalf("expected a match")
+	}
+	want := "SELECT id FROM orders WHERE status = 'shipped' AND priority > 5 AND region = 'west'"
+	if rewritten != want {
+		t.Fatalf("rewritten = %q, want %q", rewritten, want)
+	}
+}
+
+func TestMatchPreservesOptimizerHintComment(t *testing.T) {
+	m := NewManager()
+	original := "SELECT id FROM orders WHERE status = 'open'"
+	replacement := "SELECT /*+ IndexScan(orders) */ id FROM orders WHERE status = 'open'"
+	if err := m.Add(original, replacement); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	rewritten, matched, err := m.Match("SELECT id FROM orders WHERE status = 'shipped'")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if !matched {
+		t.Fatalf("expected a match")
+	}
+	if !strings.Contains(rewritten, "/*+ IndexScan(orders) */") {
+		t.Fatalf("expected the optimizer hint comment to survive rewriting, got %q", rewritten)
+	}
+}
+
+func TestAddRejectsCommandMismatch(t *testing.T) {
+	m := NewManager()
+	err := m.Add("SELECT id FROM orders", "DELETE FROM orders")
+	if err == nil {
+		t.Fatalf("expected an error for mismatched commands")
+	}
+}
+
+func TestAddRejectsTableSetMismatch(t *testing.T) {
+	m := NewManager()
+	err := m.Add("SELECT id FROM orders", "SELECT id FROM customers")
+	if err == nil {
+		t.Fatalf("expected an error for a different table set")
+	}
+}
+
+func TestNoMatchForUnregisteredQuery(t *testing.T) {
+	m := NewManager()
+	_, matched, err := m.Match("SELECT id FROM orders")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if matched {
+		t.Fatalf("expected no binding to match")
+	}
+}
+
+func TestSessionManagerFallsBackToParent(t *testing.T) {
+	global := NewManager()
+	if err := global.Add("SELECT id FROM orders WHERE status = 'open'", "SELECT id FROM orders WHERE status = 'open' ORDER BY id"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	session := global.NewSession()
+
+	_, matched, err := session.Match("SELECT id FROM orders WHERE status = 'shipped'")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if !matched {
+		t.Fatalf("expected a session Manager to fall back to its parent's bindings")
+	}
+}
+
+func TestRemoveDeletesBinding(t *testing.T) {
+	m := NewManager()
+	if err := m.Add("SELECT id FROM orders WHERE status = 'open'", "SELECT id FROM orders WHERE status = 'open' ORDER BY id"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	bindings := m.List()
+	if len(bindings) != 1 {
+		t.Fatalf("expected 1 registered binding, got %d", len(bindings))
+	}
+	m.Remove(bindings[0].Digest)
+
+	_, matched, err := m.Match("SELECT id FROM orders WHERE status = 'open'")
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if matched {
+		t.Fatalf("expected no match after removing the only binding")
+	}
+}
@@ -0,0 +1,95 @@
+package sqltoken
+
+// Depths returns, for each token in toks, the parenthesis nesting depth it
+// sits at (0 for top-level tokens). The slice has the same length as toks.
+func Depths(toks []Token) []int {
+	depths := make([]int, len(toks))
+	depth := 0
+	for i, t := range toks {
+		if t.Kind == Punct && t.Text == "(" {
+			depths[i] = depth
+			depth++
+			continue
+		}
+		if t.Kind == Punct && t.Text == ")" {
+			if depth > 0 {
+				depth--
+			}
+			depths[i] = depth
+			continue
+		}
+		depths[i] = depth
+	}
+	return depths
+}
+
+// SplitTopLevel splits toks on occurrences of a depth-0 Punct token whose
+// Text equals sep (typically ",").
+func SplitTopLevel(toks []Token, sep string) [][]Token {
+	depths := Depths(toks)
+	var groups [][]Token
+	start := 0
+	for i, t := range toks {
+		if t.Kind == Punct && t.Text == sep && depths[i] == 0 {
+			groups = append(groups, toks[start:i])
+			start = i + 1
+		}
+	}
+	groups = append(groups, toks[start:])
+	return groups
+}
+
+// FindKeyword returns the index of the first depth-0 Ident token matching
+// kw, or -1 if none is found.
+func FindKeyword(toks []Token, kw string) int {
+	depths := Depths(toks)
+	for i, t := range toks {
+		if depths[i] == 0 && t.Is(kw) {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindPhrase returns the index of the first token of a depth-0 run of
+// Ident tokens matching kws in order, or -1 if none is found.
+func FindPhrase(toks []Token, kws ...string) int {
+	depths := Depths(toks)
+	for i := 0; i+len(kws) <= len(toks); i++ {
+		if depths[i] != 0 {
+			continue
+		}
+		match := true
+		for j, kw := range kws {
+			if !toks[i+j].Is(kw) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// NonTrivia returns toks with the trailing EOF token removed.
+func NonTrivia(toks []Token) []Token {
+	if len(toks) > 0 && toks[len(toks)-1].Kind == EOF {
+		return toks[:len(toks)-1]
+	}
+	return toks
+}
+
+// Text joins the Raw text of toks with single spaces, useful for rebuilding
+// a readable fragment from a token slice.
+func Text(toks []Token) string {
+	out := ""
+	for i, t := range toks {
+		if i > 0 {
+			out += " "
+		}
+		out += t.Raw
+	}
+	return out
+}
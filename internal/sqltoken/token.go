@@ -0,0 +1,213 @@
+// Package sqltoken is a small, shared SQL tokenizer used by the root
+// postgresparser package and the analysis package to turn a single
+// statement's source text into a token stream that both can walk, instead
+// of each re-implementing quoting/comment handling over raw source text.
+package sqltoken
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind identifies the lexical category of a Token.
+type Kind int
+
+const (
+	Ident Kind = iota
+	Number
+	String
+	Punct
+	EOF
+)
+
+// Token is a single lexical token.
+type Token struct {
+	Kind Kind
+	// Text is the token's literal text. For String tokens this is the
+	// unescaped value (quotes removed); for everything else it is the
+	// source text verbatim.
+	Text string
+	// Raw is the token's exact source text, including quotes for String
+	// tokens. Raw is what normalization and rewriting should use.
+	Raw string
+	Pos int
+	Line int
+	Col  int
+}
+
+// Upper returns Text upper-cased, for case-insensitive keyword comparison.
+func (t Token) Upper() string { return strings.ToUpper(t.Text) }
+
+// Is reports whether t is an Ident token matching kw case-insensitively.
+func (t Token) Is(kw string) bool { return t.Kind == Ident && strings.EqualFold(t.Text, kw) }
+
+// Lex tokenizes sql, returning an error if a string or dollar-quoted
+// literal is left unterminated.
+func Lex(sql string) ([]Token, error) {
+	var toks []Token
+	n := len(sql)
+	i := 0
+	line, col := 1, 1
+
+	advance := func(to int) {
+		for ; i < to; i++ {
+			if sql[i] == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+		}
+	}
+
+	for i < n {
+		c := sql[i]
+		start, startLine, startCol := i, line, col
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			advance(i + 1)
+		case c == '-' && i+1 < n && sql[i+1] == '-':
+			j := strings.IndexByte(sql[i:], '\n')
+			if j < 0 {
+				advance(n)
+			} else {
+				advance(i + j)
+			}
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			j := strings.Index(sql[i+2:], "*/")
+			if j < 0 {
+				return nil, fmt.Errorf("sqltoken: unterminated block comment at line %d", startLine)
+			}
+			advance(i + j + 4)
+		case c == '\'' || ((c == 'e' || c == 'E') && i+1 < n && sql[i+1] == '\''):
+			quoteStart := i
+			if c != '\'' {
+				quoteStart = i + 1
+			}
+			end, ok := findQuoteEnd(sql, quoteStart, '\'')
+			if !ok {
+				return nil, fmt.Errorf("sqltoken: unterminated string literal at line %d", startLine)
+			}
+			raw := sql[start:end]
+			advance(end)
+			toks = append(toks, Token{Kind: String, Text: unescapeQuoted(sql[quoteStart+1 : end-1], '\''), Raw: raw, Pos: start, Line: startLine, Col: startCol})
+		case c == '"':
+			end, ok := findQuoteEnd(sql, i, '"')
+			if !ok {
+				return nil, fmt.Errorf("sqltoken: unterminated quoted identifier at line %d", startLine)
+			}
+			raw := sql[start:end]
+			advance(end)
+			toks = append(toks, Token{Kind: Ident, Text: sql[start+1 : end-1], Raw: raw, Pos: start, Line: startLine, Col: startCol})
+		case c == '$':
+			if tagEnd, ok := dollarTagEnd(sql, i); ok {
+				end, ok := findDollarEnd(sql, tagEnd, sql[i:tagEnd])
+				if !ok {
+					return nil, fmt.Errorf("sqltoken: unterminated dollar-quoted string at line %d", startLine)
+				}
+				raw := sql[start:end]
+				advance(end)
+				toks = append(toks, Token{Kind: String, Text: sql[tagEnd : end-(tagEnd-start)], Raw: raw, Pos: start, Line: startLine, Col: startCol})
+			} else {
+				// Positional parameter, e.g. $1.
+				j := i + 1
+				for j < n && isDigit(sql[j]) {
+					j++
+				}
+				advance(j)
+				toks = append(toks, Token{Kind: Punct, Text: sql[start:j], Raw: sql[start:j], Pos: start, Line: startLine, Col: startCol})
+			}
+		case isIdentStart(c):
+			j := i + 1
+			for j < n && isIdentPart(sql[j]) {
+				j++
+			}
+			advance(j)
+			toks = append(toks, Token{Kind: Ident, Text: sql[start:j], Raw: sql[start:j], Pos: start, Line: startLine, Col: startCol})
+		case isDigit(c):
+			j := i + 1
+			for j < n && (isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			advance(j)
+			toks = append(toks, Token{Kind: Number, Text: sql[start:j], Raw: sql[start:j], Pos: start, Line: startLine, Col: startCol})
+		case c == ':' && i+1 < n && sql[i+1] == ':':
+			advance(i + 2)
+			toks = append(toks, Token{Kind: Punct, Text: "::", Raw: "::", Pos: start, Line: startLine, Col: startCol})
+		case c == ':' && i+1 < n && isIdentStart(sql[i+1]):
+			j := i + 1
+			for j < n && isIdentPart(sql[j]) {
+				j++
+			}
+			advance(j)
+			toks = append(toks, Token{Kind: Punct, Text: sql[start:j], Raw: sql[start:j], Pos: start, Line: startLine, Col: startCol})
+		case strings.ContainsRune("<>=!", rune(c)):
+			j := i + 1
+			for j < n && strings.ContainsRune("<>=!", rune(sql[j])) {
+				j++
+			}
+			advance(j)
+			toks = append(toks, Token{Kind: Punct, Text: sql[start:j], Raw: sql[start:j], Pos: start, Line: startLine, Col: startCol})
+		default:
+			advance(i + 1)
+			toks = append(toks, Token{Kind: Punct, Text: string(c), Raw: string(c), Pos: start, Line: startLine, Col: startCol})
+		}
+	}
+
+	toks = append(toks, Token{Kind: EOF, Pos: n, Line: line, Col: col})
+	return toks, nil
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+func isIdentStart(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_'
+}
+
+func isIdentPart(b byte) bool {
+	return isIdentStart(b) || isDigit(b)
+}
+
+// findQuoteEnd returns the index just past the closing quote of a quoted
+// literal starting at start (which must point at the opening quote),
+// treating a doubled quote as an escaped literal quote.
+func findQuoteEnd(s string, start int, quote byte) (int, bool) {
+	n := len(s)
+	i := start + 1
+	for i < n {
+		if s[i] == quote {
+			if i+1 < n && s[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1, true
+		}
+		i++
+	}
+	return 0, false
+}
+
+func unescapeQuoted(s string, quote byte) string {
+	return strings.ReplaceAll(s, string(quote)+string(quote), string(quote))
+}
+
+func dollarTagEnd(s string, i int) (int, bool) {
+	n := len(s)
+	j := i + 1
+	for j < n && (isIdentPart(s[j])) {
+		j++
+	}
+	if j < n && s[j] == '$' {
+		return j + 1, true
+	}
+	return 0, false
+}
+
+func findDollarEnd(s string, from int, tag string) (int, bool) {
+	idx := strings.Index(s[from:], tag)
+	if idx < 0 {
+		return 0, false
+	}
+	return from + idx + len(tag), true
+}
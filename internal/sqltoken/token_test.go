@@ -0,0 +1,131 @@
+package sqltoken
+
+import "testing"
+
+func lexOK(t *testing.T, sql string) []Token {
+	t.Helper()
+	toks, err := Lex(sql)
+	if err != nil {
+		t.Fatalf("Lex(%q) failed: %v", sql, err)
+	}
+	return NonTrivia(toks)
+}
+
+func TestLexIdentifiersAndPunct(t *testing.T) {
+	toks := lexOK(t, "SELECT id FROM t WHERE x = 1")
+	if len(toks) != 8 {
+		t.Fatalf("expected 8 tokens, got %d: %+v", len(toks), toks)
+	}
+	if !toks[0].Is("SELECT") || toks[0].Kind != Ident {
+		t.Fatalf("expected SELECT ident, got %+v", toks[0])
+	}
+	if toks[6].Kind != Punct || toks[6].Text != "=" {
+		t.Fatalf("expected '=' punct, got %+v", toks[6])
+	}
+}
+
+func TestLexStringLiteralsAndEscaping(t *testing.T) {
+	toks := lexOK(t, `SELECT 'it''s', E'a\nb'`)
+	if len(toks) != 4 {
+		t.Fatalf("expected 4 tokens, got %d: %+v", len(toks), toks)
+	}
+	if toks[1].Kind != String || toks[1].Text != "it's" {
+		t.Fatalf("expected unescaped string literal, got %+v", toks[1])
+	}
+	if toks[3].Raw != `E'a\nb'` {
+		t.Fatalf("expected Raw to retain the E prefix and quotes, got %q", toks[3].Raw)
+	}
+}
+
+func TestLexUnterminatedStringIsError(t *testing.T) {
+	if _, err := Lex("SELECT 'abc"); err == nil {
+		t.Fatalf("expected error for unterminated string literal")
+	}
+}
+
+func TestLexDollarQuotedStringVsPositionalParam(t *testing.T) {
+	toks := lexOK(t, "SELECT $tag$hi$tag$, $1, $2")
+	if toks[1].Kind != String || toks[1].Text != "hi" {
+		t.Fatalf("expected dollar-quoted string, got %+v", toks[1])
+	}
+	if toks[3].Kind != Punct || toks[3].Text != "$1" {
+		t.Fatalf("expected positional parameter $1, got %+v", toks[3])
+	}
+	if toks[5].Text != "$2" {
+		t.Fatalf("expected positional parameter $2, got %+v", toks[5])
+	}
+}
+
+func TestLexUnterminatedDollarQuoteIsError(t *testing.T) {
+	if _, err := Lex("SELECT $tag$hi"); err == nil {
+		t.Fatalf("expected error for unterminated dollar-quoted string")
+	}
+}
+
+func TestLexCommentsAreSkipped(t *testing.T) {
+	toks := lexOK(t, "SELECT 1 -- trailing comment\n/* block\ncomment */ FROM t")
+	if len(toks) != 4 {
+		t.Fatalf("expected comments to produce no tokens, got %d: %+v", len(toks), toks)
+	}
+	if toks[2].Text != "FROM" {
+		t.Fatalf("expected FROM after comments, got %+v", toks[2])
+	}
+}
+
+func TestLexUnterminatedBlockCommentIsError(t *testing.T) {
+	if _, err := Lex("SELECT 1 /* oops"); err == nil {
+		t.Fatalf("expected error for unterminated block comment")
+	}
+}
+
+func TestLexQuotedIdentifierAndNamedParam(t *testing.T) {
+	toks := lexOK(t, `SELECT "my col", :name`)
+	if toks[1].Kind != Ident || toks[1].Text != "my col" {
+		t.Fatalf("expected quoted identifier, got %+v", toks[1])
+	}
+	if toks[3].Kind != Punct || toks[3].Text != ":name" {
+		t.Fatalf("expected named parameter, got %+v", toks[3])
+	}
+}
+
+func TestLexCastOperator(t *testing.T) {
+	toks := lexOK(t, "SELECT x::int")
+	if toks[2].Text != "::" {
+		t.Fatalf("expected :: cast operator, got %+v", toks[2])
+	}
+}
+
+func TestDepthsTracksParenNesting(t *testing.T) {
+	toks := lexOK(t, "a (b (c) d) e")
+	depths := Depths(toks)
+	want := []int{0, 0, 1, 1, 2, 1, 1, 0, 0}
+	if len(depths) != len(want) {
+		t.Fatalf("expected %d depths, got %d: %v", len(want), len(depths), depths)
+	}
+	for i := range want {
+		if depths[i] != want[i] {
+			t.Fatalf("depth[%d] = %d, want %d (%v)", i, depths[i], want[i], depths)
+		}
+	}
+}
+
+func TestSplitTopLevelIgnoresNestedSeparators(t *testing.T) {
+	toks := lexOK(t, "a, f(b, c), d")
+	groups := SplitTopLevel(toks, ",")
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 top-level groups, got %d: %+v", len(groups), groups)
+	}
+}
+
+func TestFindKeywordAndFindPhrase(t *testing.T) {
+	toks := lexOK(t, "SELECT 1 FROM t GROUP BY x")
+	if idx := FindKeyword(toks, "FROM"); idx != 2 {
+		t.Fatalf("expected FROM at index 2, got %d", idx)
+	}
+	if idx := FindPhrase(toks, "GROUP", "BY"); idx != 4 {
+		t.Fatalf("expected GROUP BY at index 4, got %d", idx)
+	}
+	if idx := FindPhrase(toks, "ORDER", "BY"); idx != -1 {
+		t.Fatalf("expected no ORDER BY, got index %d", idx)
+	}
+}
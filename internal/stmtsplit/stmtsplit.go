@@ -0,0 +1,140 @@
+// Package stmtsplit splits a batch of SQL text into individual statements,
+// tracking the byte offset of each statement within the original source.
+//
+// The splitter only tracks enough lexical state (string/identifier quoting,
+// dollar-quoted bodies, comments, and paren nesting) to find top-level
+// statement-separating semicolons. It does not otherwise validate or parse
+// the SQL, so it is safe to run ahead of a full parse.
+package stmtsplit
+
+import "strings"
+
+// Statement is one statement extracted from a larger batch of SQL text.
+type Statement struct {
+	// SQL is the statement text with the separating semicolon removed and
+	// surrounding whitespace trimmed.
+	SQL string
+	// Offset is the byte offset of SQL's first rune within the original
+	// input passed to Split.
+	Offset int
+}
+
+// Split breaks sql into its constituent statements, respecting dollar
+// quoting ($$...$$ and $tag$...$tag$), single-quoted and E'...' strings,
+// double-quoted identifiers, "--" and "/* */" comments, and parenthesis
+// nesting. Empty statements, such as those between repeated ";;", are
+// dropped rather than returned as blank entries.
+func Split(sql string) ([]Statement, error) {
+	var (
+		stmts []Statement
+		start int
+		depth int
+		i     int
+		n     = len(sql)
+	)
+
+	appendStmt := func(end int) {
+		text := sql[start:end]
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			return
+		}
+		lead := strings.Index(text, trimmed)
+		stmts = append(stmts, Statement{SQL: trimmed, Offset: start + lead})
+	}
+
+	for i < n {
+		c := sql[i]
+		switch {
+		case c == '-' && i+1 < n && sql[i+1] == '-':
+			if j := strings.IndexByte(sql[i:], '\n'); j < 0 {
+				i = n
+			} else {
+				i += j + 1
+			}
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			if j := strings.Index(sql[i+2:], "*/"); j < 0 {
+				i = n
+			} else {
+				i += j + 4
+			}
+		case c == '\'':
+			i = skipQuoted(sql, i, '\'')
+		case c == '"':
+			i = skipQuoted(sql, i, '"')
+		case (c == 'e' || c == 'E') && i+1 < n && sql[i+1] == '\'':
+			i = skipQuoted(sql, i+1, '\'')
+		case c == '$':
+			if tagEnd, ok := dollarTagEnd(sql, i); ok {
+				i = skipDollarQuoted(sql, i, tagEnd)
+			} else {
+				i++
+			}
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+			i++
+		case c == ';' && depth == 0:
+			appendStmt(i)
+			i++
+			start = i
+		default:
+			i++
+		}
+	}
+	appendStmt(n)
+	return stmts, nil
+}
+
+// skipQuoted returns the index just past the closing quote of a ' or "
+// delimited string starting at i (which must point at the opening quote),
+// treating a doubled quote ('' or "") as an escaped literal quote rather
+// than the end of the string.
+func skipQuoted(s string, i int, quote byte) int {
+	n := len(s)
+	i++
+	for i < n {
+		if s[i] == quote {
+			if i+1 < n && s[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return n
+}
+
+// dollarTagEnd reports whether a dollar-quote tag ($$ or $tag$) starts at i,
+// returning the index just past the opening tag's closing '$'.
+func dollarTagEnd(s string, i int) (int, bool) {
+	n := len(s)
+	j := i + 1
+	for j < n && (isAlnum(s[j]) || s[j] == '_') {
+		j++
+	}
+	if j < n && s[j] == '$' {
+		return j + 1, true
+	}
+	return 0, false
+}
+
+func isAlnum(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// skipDollarQuoted returns the index just past the closing tag of a
+// dollar-quoted string whose opening tag is s[start:tagEnd].
+func skipDollarQuoted(s string, start, tagEnd int) int {
+	tag := s[start:tagEnd]
+	close := strings.Index(s[tagEnd:], tag)
+	if close < 0 {
+		return len(s)
+	}
+	return tagEnd + close + len(tag)
+}
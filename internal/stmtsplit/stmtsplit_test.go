@@ -0,0 +1,69 @@
+package stmtsplit
+
+import "testing"
+
+func TestSplitBasicBatch(t *testing.T) {
+	stmts, err := Split("SELECT 1; SELECT 2;")
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %+v", len(stmts), stmts)
+	}
+	if stmts[0].SQL != "SELECT 1" || stmts[1].SQL != "SELECT 2" {
+		t.Fatalf("unexpected statement text: %+v", stmts)
+	}
+	if stmts[1].Offset != 10 {
+		t.Fatalf("expected second statement offset 10, got %d", stmts[1].Offset)
+	}
+}
+
+func TestSplitDropsEmptyStatements(t *testing.T) {
+	stmts, err := Split("SELECT 1;; ;  SELECT 2;")
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected empty statements to be dropped, got %d: %+v", len(stmts), stmts)
+	}
+}
+
+func TestSplitIgnoresSemicolonInsideString(t *testing.T) {
+	stmts, err := Split(`SELECT 'a;b''c;d' FROM t;`)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(stmts) != 1 {
+		t.Fatalf("expected 1 statement, got %d: %+v", len(stmts), stmts)
+	}
+}
+
+func TestSplitIgnoresSemicolonInsideComment(t *testing.T) {
+	stmts, err := Split("SELECT 1 -- a; b\n; SELECT /* x; y */ 2;")
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %+v", len(stmts), stmts)
+	}
+}
+
+func TestSplitIgnoresSemicolonInsideDollarQuote(t *testing.T) {
+	stmts, err := Split("CREATE FUNCTION f() RETURNS void AS $$ BEGIN SELECT 1; END; $$ LANGUAGE plpgsql;")
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(stmts) != 1 {
+		t.Fatalf("expected dollar-quoted body to stay one statement, got %d: %+v", len(stmts), stmts)
+	}
+}
+
+func TestSplitIgnoresSemicolonInsideParens(t *testing.T) {
+	stmts, err := Split("CREATE TABLE t (a int, b int); SELECT 1;")
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %+v", len(stmts), stmts)
+	}
+}
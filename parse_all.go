@@ -0,0 +1,30 @@
+package postgresparser
+
+import "github.com/tsingson/postgresparser/internal/stmtsplit"
+
+// ParseSQLAll splits sql into its constituent statements and parses each one
+// independently, returning one Result per statement in source order. Unlike
+// ParseSQL, which only looks at the first statement of a batch, ParseSQLAll
+// lets tooling such as linters, replayers, and migration checkers reason
+// about an entire script and, via Result.Offset, point findings back at the
+// original text.
+func ParseSQLAll(sql string) ([]*Result, error) {
+	stmts, err := stmtsplit.Split(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Result, 0, len(stmts))
+	for _, stmt := range stmts {
+		res, err := ParseSQL(stmt.SQL)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			continue
+		}
+		res.Offset = stmt.Offset
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,27 @@
+package postgresparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSQLAllSplitsAndOffsetsStatements(t *testing.T) {
+	results, err := ParseSQLAll("SELECT 1; INSERT INTO t VALUES (1); SET client_min_messages = warning;")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, QueryCommandSelect, results[0].Command)
+	assert.Equal(t, 0, results[0].Offset)
+
+	assert.Equal(t, QueryCommandInsert, results[1].Command)
+	assert.Greater(t, results[1].Offset, 0)
+
+	assert.Equal(t, QueryCommandUnknown, results[2].Command)
+}
+
+func TestParseSQLAllStopsAtFirstError(t *testing.T) {
+	_, err := ParseSQLAll("SELECT 1; SHOW;")
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+// Package postgresparser turns a single PostgreSQL statement into a small
+// intermediate representation (Result) describing its top-level command.
+// The richer, tool-facing DTO built on top of this IR lives in the
+// analysis subpackage.
+package postgresparser
+
+import (
+	"fmt"
+
+	"github.com/tsingson/postgresparser/internal/sqltoken"
+	"github.com/tsingson/postgresparser/internal/stmtsplit"
+)
+
+// ParseSQL parses sql and returns its Result. sql may contain more than one
+// statement, in which case only the first is parsed; use ParseSQLAll to
+// process every statement in a batch.
+func ParseSQL(sql string) (*Result, error) {
+	stmts, err := stmtsplit.Split(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("postgresparser: empty statement")
+	}
+
+	toks, err := sqltoken.Lex(stmts[0].SQL)
+	if err != nil {
+		return nil, err
+	}
+	toks = sqltoken.NonTrivia(toks)
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("postgresparser: empty statement")
+	}
+
+	if isUtilityStart(toks) {
+		if err := validateUtility(toks); err != nil {
+			return nil, err
+		}
+		return &Result{Command: QueryCommandUnknown}, nil
+	}
+
+	var command QueryCommand
+	switch {
+	case toks[0].Is("SELECT"):
+		command = QueryCommandSelect
+	case toks[0].Is("INSERT"):
+		command = QueryCommandInsert
+	case toks[0].Is("UPDATE"):
+		command = QueryCommandUpdate
+	case toks[0].Is("DELETE"):
+		command = QueryCommandDelete
+	default:
+		return nil, fmt.Errorf("postgresparser: unrecognized statement starting with %q", toks[0].Text)
+	}
+
+	if err := checkBalancedParens(toks); err != nil {
+		return nil, err
+	}
+	return &Result{Command: command}, nil
+}
+
+func checkBalancedParens(toks []sqltoken.Token) error {
+	depth := 0
+	for _, t := range toks {
+		if t.Kind != sqltoken.Punct {
+			continue
+		}
+		switch t.Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("postgresparser: unmatched ')' at line %d", t.Line)
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("postgresparser: unmatched '(' ")
+	}
+	return nil
+}
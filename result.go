@@ -0,0 +1,23 @@
+package postgresparser
+
+// QueryCommand identifies the top-level SQL command represented by a Result.
+type QueryCommand string
+
+const (
+	QueryCommandSelect  QueryCommand = "SELECT"
+	QueryCommandInsert  QueryCommand = "INSERT"
+	QueryCommandUpdate  QueryCommand = "UPDATE"
+	QueryCommandDelete  QueryCommand = "DELETE"
+	QueryCommandUnknown QueryCommand = "UNKNOWN"
+)
+
+// Result is the intermediate representation produced by ParseSQL for a
+// single SQL statement.
+type Result struct {
+	Command QueryCommand
+
+	// Offset is the byte offset, in the source passed to ParseSQLAll, of
+	// the statement this result describes. It is zero for results
+	// produced directly by ParseSQL.
+	Offset int
+}
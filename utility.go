@@ -0,0 +1,159 @@
+package postgresparser
+
+import (
+	"fmt"
+
+	"github.com/tsingson/postgresparser/internal/sqltoken"
+)
+
+// IsUtilityStatement reports whether toks begins a SET/SHOW/RESET
+// statement, or an "ALTER SYSTEM SET" statement. Other packages in this
+// module (e.g. analysis) use it to share this grammar instead of
+// reimplementing it.
+func IsUtilityStatement(toks []sqltoken.Token) bool {
+	return isUtilityStart(toks)
+}
+
+// ValidateUtilityStatement validates the grammar of a statement for which
+// IsUtilityStatement already returned true.
+func ValidateUtilityStatement(toks []sqltoken.Token) error {
+	return validateUtility(toks)
+}
+
+// isUtilityStart reports whether toks begins a SET/SHOW/RESET statement, or
+// an "ALTER SYSTEM SET" statement.
+func isUtilityStart(toks []sqltoken.Token) bool {
+	if len(toks) == 0 {
+		return false
+	}
+	if toks[0].Is("SET") || toks[0].Is("SHOW") || toks[0].Is("RESET") {
+		return true
+	}
+	return toks[0].Is("ALTER") && len(toks) > 1 && toks[1].Is("SYSTEM")
+}
+
+// validateUtility validates the grammar of a statement for which
+// isUtilityStart already returned true.
+func validateUtility(toks []sqltoken.Token) error {
+	switch {
+	case toks[0].Is("SET"):
+		return validateSet(toks)
+	case toks[0].Is("SHOW"):
+		return validateShowOrReset(toks[1:], "SHOW")
+	case toks[0].Is("RESET"):
+		return validateShowOrReset(toks[1:], "RESET")
+	default: // ALTER SYSTEM ...
+		if len(toks) < 3 || !toks[2].Is("SET") {
+			return fmt.Errorf("postgresparser: expected SET after ALTER SYSTEM")
+		}
+		return validateSet(toks[2:])
+	}
+}
+
+// validateSet validates everything after (and including) the leading SET
+// keyword in toks.
+func validateSet(toks []sqltoken.Token) error {
+	rest := toks[1:]
+	if len(rest) == 0 {
+		return fmt.Errorf("postgresparser: SET requires a parameter name")
+	}
+
+	if rest[0].Is("ROLE") {
+		if len(rest) != 2 {
+			return fmt.Errorf("postgresparser: SET ROLE requires exactly one value")
+		}
+		return nil
+	}
+
+	modifierSeen := false
+	for len(rest) > 0 && isSetModifier(rest) {
+		if modifierSeen {
+			return fmt.Errorf("postgresparser: SET accepts at most one of SESSION or LOCAL")
+		}
+		modifierSeen = true
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return fmt.Errorf("postgresparser: SET requires a parameter name")
+	}
+
+	if rest[0].Is("SESSION") && len(rest) > 1 && rest[1].Is("AUTHORIZATION") {
+		if len(rest) != 3 {
+			return fmt.Errorf("postgresparser: SET SESSION AUTHORIZATION requires exactly one value")
+		}
+		return nil
+	}
+
+	if rest[0].Kind != sqltoken.Ident {
+		return fmt.Errorf("postgresparser: expected a parameter name, got %q", rest[0].Text)
+	}
+	name := rest[0].Text
+	rest = rest[1:]
+
+	if len(rest) == 0 {
+		return fmt.Errorf("postgresparser: SET %s requires a value", name)
+	}
+
+	if rest[0].Is("FROM") {
+		if len(rest) != 2 || !rest[1].Is("CURRENT") {
+			return fmt.Errorf("postgresparser: expected CURRENT after FROM")
+		}
+		return nil
+	}
+
+	if !(rest[0].Text == "=" || rest[0].Is("TO")) {
+		return fmt.Errorf("postgresparser: expected = or TO after %s", name)
+	}
+	rest = rest[1:]
+
+	if len(rest) == 0 {
+		return fmt.Errorf("postgresparser: SET %s requires a value", name)
+	}
+	return validateValueList(rest, name)
+}
+
+// isSetModifier reports whether rest begins with LOCAL, or with SESSION used
+// as a modifier rather than as the start of SESSION AUTHORIZATION.
+func isSetModifier(rest []sqltoken.Token) bool {
+	if rest[0].Is("LOCAL") {
+		return true
+	}
+	if rest[0].Is("SESSION") {
+		return !(len(rest) > 1 && rest[1].Is("AUTHORIZATION"))
+	}
+	return false
+}
+
+func validateValueList(toks []sqltoken.Token, name string) error {
+	for _, group := range sqltoken.SplitTopLevel(toks, ",") {
+		if len(group) != 1 {
+			return fmt.Errorf("postgresparser: invalid value for %s", name)
+		}
+		switch group[0].Kind {
+		case sqltoken.Ident, sqltoken.Number, sqltoken.String:
+			// ok
+		default:
+			return fmt.Errorf("postgresparser: invalid value for %s: %q", name, group[0].Text)
+		}
+	}
+	return nil
+}
+
+func validateShowOrReset(rest []sqltoken.Token, which string) error {
+	if len(rest) == 0 {
+		return fmt.Errorf("postgresparser: %s requires a parameter name", which)
+	}
+	if which == "RESET" && rest[0].Is("TIME") {
+		if len(rest) != 2 || !rest[1].Is("ZONE") {
+			return fmt.Errorf("postgresparser: expected ZONE after TIME")
+		}
+		return nil
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("postgresparser: unexpected trailing tokens after %s", which)
+	}
+	if rest[0].Kind != sqltoken.Ident {
+		return fmt.Errorf("postgresparser: expected a parameter name")
+	}
+	return nil
+}